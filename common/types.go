@@ -1,19 +1,76 @@
 package common
 
+import "time"
+
 // GetRoutesRequest has the expected parameters for GetRoutes request
 type GetRoutesRequest struct {
-	Source      string `json:"source"`
-	Destination string `json:"destination"`
-	StartTime   string `json:"startTime"` // Optional
+	Source        string   `json:"source"`
+	Destination   string   `json:"destination"`
+	StartTime     string   `json:"startTime"`     // Optional
+	ArriveBy      string   `json:"arriveBy"`      // Optional. The time by which to arrive at the destination, can't be used with startTime
+	AsOf          string   `json:"asOf"`          // Optional. The date for which the network is considered, defaults to the date of startTime
+	MaxRoutes     int      `json:"maxRoutes"`     // Optional. The maximum number of routes to suggest, defaults to 3
+	Sort          string   `json:"sort"`          // Optional. "fare" orders the routes by the adult card fare
+	AvoidStations []string `json:"avoidStations"` // Optional. The station names or codes that the routes don't go through, either repeated or comma separated
+	AvoidLines    []string `json:"avoidLines"`    // Optional. The train line codes that the routes don't travel on, either repeated or comma separated
+	Via           string   `json:"via"`           // Optional. The station name or code that the routes go through
+	Optimize      string   `json:"optimize"`      // Optional. time, stations or transfers. Defaults to time if there's startTime or arriveBy else stations
+	MaxTransfers  *int     `json:"maxTransfers"`  // Optional. The maximum number of line changes in a route
+	Format        string   `json:"format"`        // Optional. "geojson" returns the routes as a GeoJSON FeatureCollection
+	FromLatLon    string   `json:"fromLatLon"`    // Optional. The latitude and longitude to start from instead of the source e.g. 1.3521,103.8198
+	ToLatLon      string   `json:"toLatLon"`      // Optional. The latitude and longitude to end at instead of the destination
 }
 
 // Route has the suggested route with the metadata about route
 type SuggestedRoute struct {
-	StationsTravelled      int64    `json:"stationsTravelled"`
-	Route                  []string `json:"route"`
-	VerboseRoute           []string `json:"verboseRoute"`
-	EstimatedTimeInMinutes int64    `json:"estimatedTimeInMinutes"`
-	ShortestRoute          bool     `json:"shortestRoute"` // This will denote whether it's the shortest route
+	StationsTravelled      int64             `json:"stationsTravelled"`
+	Route                  []string          `json:"route"`
+	VerboseRoute           []string          `json:"verboseRoute"`
+	Legs                   []*LegInfo        `json:"legs"`
+	Summary                string            `json:"summary"` // The condensed instructions e.g. "Take EW line 6 stops to Buona Vista, change to CC line 2 stops to Holland Village"
+	EstimatedTimeInMinutes int64             `json:"estimatedTimeInMinutes"`
+	TransferCount          int64             `json:"transferCount"`           // The number of line changes
+	Lines                  []string          `json:"lines"`                   // The train line codes in the order they are travelled on
+	DepartureTime          string            `json:"departureTime,omitempty"` // The start time, or the latest time to depart from the source to arrive by the arriveBy time
+	ArrivalTime            string            `json:"arrivalTime,omitempty"`   // The time of arrival at the destination
+	ShortestRoute          bool              `json:"shortestRoute"`           // This will denote whether it's the shortest route
+	Fare                   *FareInfo         `json:"fare,omitempty"`
+	Disruptions            []*DisruptionInfo `json:"disruptions,omitempty"` // The active disruptions on the train lines of the route that it was planned around
+	StartWalk              *WalkInfo         `json:"startWalk,omitempty"`   // The walk from fromLatLon to the first station
+	EndWalk                *WalkInfo         `json:"endWalk,omitempty"`     // The walk from the last station to toLatLon
+}
+
+// LegInfo is a part of a route travelled on a single train line, or a walk between two nearby stations
+type LegInfo struct {
+	Line                 string   `json:"line,omitempty"`    // Empty for a walk
+	Towards              string   `json:"towards,omitempty"` // The last station of the train line in the direction of travel
+	Walk                 bool     `json:"walk"`
+	BoardStation         string   `json:"boardStation"` // The station where the train is boarded or the walk starts
+	AlightStation        string   `json:"alightStation"`
+	Stops                int      `json:"stops"`                // The number of stations travelled
+	IntermediateStations []string `json:"intermediateStations"` // The stations between the board and alight stations in the order of travel
+	TimeInMinutes        int64    `json:"timeInMinutes"`        // Including the time to change to the line. This is 0 without startTime or arriveBy
+}
+
+// WalkInfo has the details of a walk between a location and a station
+type WalkInfo struct {
+	Station          string `json:"station"`
+	DistanceInMeters int64  `json:"distanceInMeters"` // The straight line distance to the nearest platform of the station
+	TimeInMinutes    int64  `json:"timeInMinutes"`
+}
+
+// FareInfo has the fares of a route for each fare class
+type FareInfo struct {
+	DistanceInKm float64         `json:"distanceInKm"`
+	Adult        *FareAmountInfo `json:"adult"`
+	Student      *FareAmountInfo `json:"student"`
+	Senior       *FareAmountInfo `json:"senior"`
+}
+
+// FareAmountInfo has the fares in cents of a fare class paying by card and by a single trip ticket
+type FareAmountInfo struct {
+	CardInCents       int64 `json:"cardInCents"`
+	SingleTripInCents int64 `json:"singleTripInCents"`
 }
 
 // GetRoutesResponse has the response for get route request
@@ -23,10 +80,160 @@ type GetRoutesResponse struct {
 	SuggestedRoutes []*SuggestedRoute `json:"suggestedRoutes"`
 }
 
+// RouteFeatureCollection has the response for get route request in the geojson format
+type RouteFeatureCollection struct {
+	Type     string          `json:"type"` // FeatureCollection
+	Features []*RouteFeature `json:"features"`
+}
+
+// RouteFeature is a suggested route as a GeoJSON feature
+type RouteFeature struct {
+	Type       string                  `json:"type"` // Feature
+	Geometry   *LineStringGeometry     `json:"geometry"`
+	Properties *RouteFeatureProperties `json:"properties"`
+}
+
+// LineStringGeometry is a GeoJSON LineString through the stations of a route
+type LineStringGeometry struct {
+	Type        string       `json:"type"`        // LineString
+	Coordinates [][2]float64 `json:"coordinates"` // The longitude and latitude of the stations in the order of travel
+}
+
+// RouteFeatureProperties has the details of the suggested route along with the parts of the route on each train line
+type RouteFeatureProperties struct {
+	*SuggestedRoute
+	LineSegments []*LineSegmentInfo `json:"lineSegments"`
+}
+
+// LineSegmentInfo is a part of a route travelled on a single train line, or a walk between two nearby stations
+type LineSegmentInfo struct {
+	Line       string `json:"line,omitempty"` // Empty for a walk
+	Walk       bool   `json:"walk"`
+	StartIndex int    `json:"startIndex"` // The index of the first station of the segment in the route and the coordinates
+	EndIndex   int    `json:"endIndex"`   // The index of the last station of the segment in the route and the coordinates
+}
+
+// GetStationsRequest has the expected parameters for GetStations request
+type GetStationsRequest struct {
+	Line string `json:"line"` // Optional. Only the stations on the train line are returned if provided
+}
+
+// StationInfo has the details of a station across all the train lines serving it
+type StationInfo struct {
+	Name        string       `json:"name"`
+	Codes       []string     `json:"codes"`
+	Lines       []string     `json:"lines"`
+	OpeningDate string       `json:"openingDate"`           // The earliest opening date among the train lines serving the station
+	Interchange bool         `json:"interchange"`           // This will denote whether the station is served by more than one train line
+	Coordinates *Coordinates `json:"coordinates,omitempty"` // The location of the first platform of the station that has coordinates
+}
+
+// Coordinates is the location of a station
+type Coordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// GetStationsResponse has the response for get stations request
+type GetStationsResponse struct {
+	Stations []*StationInfo `json:"stations"`
+}
+
+// SearchStationsRequest has the expected parameters for SearchStations request
+type SearchStationsRequest struct {
+	Query string `json:"q" schema:"q"`
+	Limit int    `json:"limit"` // Optional. Defaults to 10
+}
+
+// SearchStationsResponse has the response for search stations request
+type SearchStationsResponse struct {
+	Query    string         `json:"query"`
+	Stations []*StationInfo `json:"stations"` // Ordered by the closeness of the match
+}
+
+// NearestStationsRequest has the expected parameters for NearestStations request
+type NearestStationsRequest struct {
+	Latitude  *float64 `json:"lat" schema:"lat"`
+	Longitude *float64 `json:"lon" schema:"lon"`
+	Limit     int      `json:"limit"` // Optional. Defaults to 5
+}
+
+// NearbyStationInfo has the details of a station near a location
+type NearbyStationInfo struct {
+	*StationInfo
+	DistanceInMeters     int64 `json:"distanceInMeters"` // The straight line distance to the nearest platform of the station
+	WalkingTimeInMinutes int64 `json:"walkingTimeInMinutes"`
+}
+
+// NearestStationsResponse has the response for nearest stations request
+type NearestStationsResponse struct {
+	Stations []*NearbyStationInfo `json:"stations"` // Ordered by the distance
+}
+
+// LineStation is a station in the order of travel on a train line
+type LineStation struct {
+	Code             string       `json:"code"`
+	Name             string       `json:"name"`
+	OpeningDate      string       `json:"openingDate"`
+	InterchangeLines []string     `json:"interchangeLines"` // The other train lines that can be changed to at the station
+	Coordinates      *Coordinates `json:"coordinates,omitempty"`
+}
+
+// LineInfo has the stations of a train line in the order of travel
+type LineInfo struct {
+	Code         string         `json:"code"`
+	Termini      []string       `json:"termini"` // The names of the stations at the ends of the train line
+	StationCount int            `json:"stationCount"`
+	Stations     []*LineStation `json:"stations"`
+	Interchanges []string       `json:"interchanges"` // The codes of the stations on the train line where the line can be changed
+}
+
+// GetLinesResponse has the response for get lines request
+type GetLinesResponse struct {
+	Lines []*LineInfo `json:"lines"`
+}
+
+// ReloadNetworkResponse has the response for reload network request
+type ReloadNetworkResponse struct {
+	StationCount int `json:"stationCount"`
+	LineCount    int `json:"lineCount"`
+}
+
+// DisruptionRequest has the expected body of the create and update disruption requests
+type DisruptionRequest struct {
+	Type        string `json:"type"`        // station, segment or line
+	Station     string `json:"station"`     // The station name or code closed by a station disruption
+	From        string `json:"from"`        // The station code from which a segment disruption closes the line
+	To          string `json:"to"`          // The station code up to which a segment disruption closes the line
+	Line        string `json:"line"`        // The train line code closed by a line disruption
+	Start       string `json:"start"`       // The time from which the disruption is active
+	End         string `json:"end"`         // Optional. The time until which the disruption is active, it is active until it is deleted if not provided
+	Description string `json:"description"` // Optional
+}
+
+// DisruptionInfo has the details of a disruption
+type DisruptionInfo struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Station     string `json:"station,omitempty"`
+	From        string `json:"from,omitempty"`
+	To          string `json:"to,omitempty"`
+	Line        string `json:"line,omitempty"`
+	Start       string `json:"start"`
+	End         string `json:"end,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// GetDisruptionsResponse has the response for get disruptions request
+type GetDisruptionsResponse struct {
+	Disruptions []*DisruptionInfo `json:"disruptions"` // Ordered by the start time
+}
+
 // ErrorResponse
 type ErrorResponse struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code        int      `json:"code"`
+	Message     string   `json:"message"`
+	Suggestions []string `json:"suggestions,omitempty"` // The station names that may have been meant if a station name is not recognised
 }
 
 // RouteNode is used for internal operation to fetch the routes from source to destination
@@ -34,8 +241,9 @@ type RouteNode struct {
 	Station          *Station
 	PrevNode         *RouteNode
 	NextNode         *RouteNode
-	StationCount     int64
-	EstimatedTime    int64
+	StationCount     int64 // The number of stations travelled to reach the node
+	TransferCount    int64 // The number of line changes to reach the node
+	EstimatedTime    int64 // The time elapsed in minutes to reach the node which is used to evaluate the next segment at the time it is travelled
 	IsNotOperational bool
 }
 
@@ -44,6 +252,7 @@ type Station struct {
 	Code           string     `json:"code"`
 	Name           string     `json:"name"`
 	OpeningDate    string     `json:"openingDate"`
+	OpenedOn       time.Time  `json:"-"` // Parsed value of OpeningDate, zero value denotes that the station has always been open
 	LinkedStations []*Station `json:"linkedStations"`
 	NextStation    *Station   `json:"nextStation"`
 	PrevStation    *Station   `json:"prevStation"`
@@ -0,0 +1,17 @@
+package data
+
+import (
+	_ "embed" // required for embedding the station map and the fare table
+)
+
+// StationMap is the station map csv that is compiled into the binary
+// It is used when the path to a station map isn't provided
+//
+//go:embed StationMap.csv
+var StationMap []byte
+
+// FareTable is the distance band fare table csv that is compiled into the binary
+// It is used when the path to a fare table isn't provided
+//
+//go:embed FareTable.csv
+var FareTable []byte
@@ -0,0 +1,145 @@
+package disruptions
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+	"gitlab.myteksi.net/goscripts/zendesk/routing"
+	"gitlab.myteksi.net/goscripts/zendesk/utils"
+)
+
+const DISRUPTION_TIME_FORMAT = "2006-01-02T15:04" // This is the expected format of the start and end times in the network timezone. RFC3339 is accepted too
+
+type IHandler interface {
+	Handle(w http.ResponseWriter, r *http.Request)
+	HandleDisruption(w http.ResponseWriter, r *http.Request)
+	HandleCreate(w http.ResponseWriter, r *http.Request)
+	HandleUpdate(w http.ResponseWriter, r *http.Request)
+	HandleDelete(w http.ResponseWriter, r *http.Request)
+}
+
+type handler struct {
+	store    *routing.DisruptionStore
+	networks routing.NetworkProvider
+	location *time.Location
+}
+
+// NewHandlerImpl returns the handler which manages the disruptions in the store
+// The disruptions are validated against the network in use and the times without a timezone are in the location
+func NewHandlerImpl(store *routing.DisruptionStore, networks routing.NetworkProvider, location *time.Location) IHandler {
+	return &handler{store: store, networks: networks, location: location}
+}
+
+// Handle method would return all the disruptions ordered by the start time
+func (h *handler) Handle(w http.ResponseWriter, r *http.Request) {
+	disruptionInfos := []*common.DisruptionInfo{}
+	for _, disruption := range h.store.Disruptions() {
+		disruptionInfos = append(disruptionInfos, disruption.Info())
+	}
+	utils.WriteSuccessResponse(w, 200, &common.GetDisruptionsResponse{Disruptions: disruptionInfos})
+}
+
+// HandleDisruption method would return the disruption with the id in the path
+func (h *handler) HandleDisruption(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	disruption, ok := h.store.Disruption(id)
+	if !ok {
+		utils.WriteErrorResponse(fmt.Errorf("disruption %s not found", id), w, 404)
+		return
+	}
+	utils.WriteSuccessResponse(w, 200, disruption.Info())
+}
+
+// HandleCreate method would add the disruption in the request body
+func (h *handler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	disruption, err := h.decodeDisruption(r)
+	if err != nil {
+		utils.WriteErrorResponse(err, w, 400)
+		return
+	}
+	disruption, err = h.store.Add(disruption)
+	if err != nil {
+		utils.WriteErrorResponse(err, w, 500)
+		return
+	}
+	utils.WriteSuccessResponse(w, 201, disruption.Info())
+}
+
+// HandleUpdate method would replace the disruption with the id in the path with the disruption in the request body
+func (h *handler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	disruption, err := h.decodeDisruption(r)
+	if err != nil {
+		utils.WriteErrorResponse(err, w, 400)
+		return
+	}
+	disruption, ok, err := h.store.Update(id, disruption)
+	if !ok {
+		utils.WriteErrorResponse(fmt.Errorf("disruption %s not found", id), w, 404)
+		return
+	}
+	if err != nil {
+		utils.WriteErrorResponse(err, w, 500)
+		return
+	}
+	utils.WriteSuccessResponse(w, 200, disruption.Info())
+}
+
+// HandleDelete method would remove the disruption with the id in the path
+func (h *handler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	ok, err := h.store.Remove(id)
+	if !ok {
+		utils.WriteErrorResponse(fmt.Errorf("disruption %s not found", id), w, 404)
+		return
+	}
+	if err != nil {
+		utils.WriteErrorResponse(err, w, 500)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+// decodes the disruption in the request body and validates it against the network in use
+func (h *handler) decodeDisruption(r *http.Request) (*routing.Disruption, error) {
+	req := &common.DisruptionRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		return nil, fmt.Errorf("invalid disruption: %v", err)
+	}
+	disruption := &routing.Disruption{
+		Type:        strings.ToLower(strings.TrimSpace(req.Type)),
+		Station:     strings.TrimSpace(req.Station),
+		From:        strings.ToUpper(strings.TrimSpace(req.From)),
+		To:          strings.ToUpper(strings.TrimSpace(req.To)),
+		Line:        strings.ToUpper(strings.TrimSpace(req.Line)),
+		Description: req.Description,
+	}
+	var err error
+	if req.Start != "" {
+		if disruption.Start, err = h.parseDisruptionTime(req.Start); err != nil {
+			return nil, fmt.Errorf("invalid start time")
+		}
+	}
+	if req.End != "" {
+		if disruption.End, err = h.parseDisruptionTime(req.End); err != nil {
+			return nil, fmt.Errorf("invalid end time")
+		}
+	}
+	if err := h.networks.Network().ValidateDisruption(disruption); err != nil {
+		return nil, err
+	}
+	return disruption, nil
+}
+
+// parses the time which is either in DISRUPTION_TIME_FORMAT in the network timezone or RFC3339
+func (h *handler) parseDisruptionTime(value string) (time.Time, error) {
+	if disruptionTime, err := time.ParseInLocation(DISRUPTION_TIME_FORMAT, value, h.location); err == nil {
+		return disruptionTime, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,42 @@
+package getlines
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+	"gitlab.myteksi.net/goscripts/zendesk/routing"
+	"gitlab.myteksi.net/goscripts/zendesk/utils"
+)
+
+type IHandler interface {
+	Handle(w http.ResponseWriter, r *http.Request)
+	HandleLine(w http.ResponseWriter, r *http.Request)
+}
+
+type handler struct {
+	networks routing.NetworkProvider
+}
+
+// NewHandlerImpl returns the handler which lists the train lines on the network provided for each request
+func NewHandlerImpl(networks routing.NetworkProvider) IHandler {
+	return &handler{networks: networks}
+}
+
+// Handle method would return all the train lines with their stations in the order of travel
+func (h *handler) Handle(w http.ResponseWriter, r *http.Request) {
+	utils.WriteSuccessResponse(w, 200, &common.GetLinesResponse{Lines: h.networks.Network().Lines()})
+}
+
+// HandleLine method would return the train line in the path with its stations in the order of travel
+func (h *handler) HandleLine(w http.ResponseWriter, r *http.Request) {
+	lineCode := strings.ToUpper(mux.Vars(r)["code"])
+	lineInfo, ok := h.networks.Network().Line(lineCode)
+	if !ok {
+		utils.WriteErrorResponse(fmt.Errorf("line %s not found", lineCode), w, 404)
+		return
+	}
+	utils.WriteSuccessResponse(w, 200, lineInfo)
+}
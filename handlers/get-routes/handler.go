@@ -1,43 +1,40 @@
 package getroutes
 
 import (
-	"encoding/json"
 	"fmt"
 	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
-	"gitlab.myteksi.net/goscripts/zendesk/utils"
-
-	"github.com/thoas/go-funk"
-
 	"github.com/gorilla/schema"
 	"gitlab.myteksi.net/goscripts/zendesk/common"
+	"gitlab.myteksi.net/goscripts/zendesk/routing"
+	"gitlab.myteksi.net/goscripts/zendesk/utils"
 )
 
 const (
-	INVALID_PREV_STATION_NUMBER = int64(-1)            // This is used in finding the closest station while inserting a new station
-	INVALID_NEXT_STATION_NUMBER = int64(math.MaxInt64) // This is used in finding the closest station while inserting a new station
-	QUERY_TIME_FORMAT           = "2006-01-02T15:04"   // This is the expected format in which startTime parameter in getQueryRoutes is expected
-	DEFAULT_KEY                 = "default"            // For the TrainLineTimeExceptionRules map, for default values this will be the key
+	QUERY_TIME_FORMAT = "2006-01-02T15:04" // This is the expected format in which startTime and arriveBy parameters in getQueryRoutes are expected in the network timezone. RFC3339 and epoch seconds are accepted too
+	NOW_QUERY_TIME    = "now"              // This can be passed as the startTime to start the journey at the current time
+	AS_OF_DATE_FORMAT = "2006-01-02"       // This is the expected format in which asOf parameter in getQueryRoutes is expected
+	FARE_SORT         = "fare"             // This can be passed as the sort to order the routes by the adult card fare
+	GEOJSON_FORMAT    = "geojson"          // This can be passed as the format to return the routes as a GeoJSON FeatureCollection
 )
 
-var decoder *schema.Decoder
-
-// init function is automatically executed on package load
-func init() {
-	buildTrainLineMap()
-	decoder = schema.NewDecoder()
-}
+var decoder = schema.NewDecoder()
 
 type IHandler interface {
 	Handle(w http.ResponseWriter, r *http.Request)
 }
 
-type handler struct{}
+type handler struct {
+	planner *routing.Planner
+}
 
-func NewHandlerImpl() IHandler {
-	return &handler{}
+// NewHandlerImpl returns the handler which plans the routes using the planner
+func NewHandlerImpl(planner *routing.Planner) IHandler {
+	return &handler{planner: planner}
 }
 
 // Handle method would return the response to be returned to the API
@@ -46,155 +43,267 @@ func (h *handler) Handle(w http.ResponseWriter, r *http.Request) {
 	routeRequest := &common.GetRoutesRequest{}
 	err := decoder.Decode(routeRequest, r.URL.Query())
 	if err != nil {
-		writeErrorResponse(err, w, 500)
+		utils.WriteErrorResponse(err, w, 400)
+		return
 	}
-	err = validateRequest(routeRequest)
+	planOptions, err := h.validateRequest(routeRequest)
 	if err != nil {
-		writeErrorResponse(err, w, 400)
+		utils.WriteErrorResponse(err, w, 400)
 		return
 	}
-	routes, err := fetchRoutes(routeRequest)
+	journeys, err := h.planner.Plan(r.Context(), routeRequest.Source, routeRequest.Destination, planOptions...)
 	if err != nil {
-		writeErrorResponse(err, w, 500)
+		if invalidRequestErr, ok := err.(*routing.InvalidRequestError); ok {
+			utils.WriteErrorResponseWithSuggestions(err, invalidRequestErr.Suggestions, w, 400)
+			return
+		}
+		utils.WriteErrorResponse(err, w, 500)
 		return
 	}
-	routeResponse, err := generateRouteResponse(routes, routeRequest)
-	if err != nil {
-		writeErrorResponse(err, w, 500)
+	if routeRequest.Format == GEOJSON_FORMAT {
+		featureCollection, err := generateRouteFeatureCollection(journeys)
+		if err != nil {
+			// The coordinates are missing from the network so the request can't be served as configured
+			utils.WriteErrorResponse(err, w, 500)
+			return
+		}
+		utils.WriteSuccessResponse(w, 200, featureCollection)
 		return
 	}
-	writeSuccessResponse(w, 200, routeResponse)
+	utils.WriteSuccessResponse(w, 200, generateRouteResponse(journeys, routeRequest))
 }
 
-// validates the request such that only startTime is optional
-func validateRequest(req *common.GetRoutesRequest) error {
+// validates the request such that only the source and destination are required and returns the options to plan the journeys with
+// The stations, lines and the value to optimize are validated by the planner
+func (h *handler) validateRequest(req *common.GetRoutesRequest) ([]routing.PlanOption, error) {
+	var planOptions []routing.PlanOption
 	// validate start time if present
 	if req.StartTime != "" {
-		_, err := time.Parse(QUERY_TIME_FORMAT, req.StartTime)
+		startTime, err := h.parseQueryTime(req.StartTime)
 		if err != nil {
-			return fmt.Errorf("invalid start time")
+			return nil, fmt.Errorf("invalid start time")
 		}
+		planOptions = append(planOptions, routing.WithStartTime(startTime))
 	}
-	if _, ok := stationNameCodeMap[req.Source]; !ok {
-		return fmt.Errorf("invalid source station")
+	// validate arrive by time if present
+	if req.ArriveBy != "" {
+		arriveBy, err := h.parseQueryTime(req.ArriveBy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid arrive by time")
+		}
+		planOptions = append(planOptions, routing.WithArriveBy(arriveBy))
 	}
-	if _, ok := stationNameCodeMap[req.Destination]; !ok {
-		return fmt.Errorf("invalid destination station")
+	// validate as of date if present
+	if req.AsOf != "" {
+		networkDate, err := time.Parse(AS_OF_DATE_FORMAT, req.AsOf)
+		if err != nil {
+			return nil, fmt.Errorf("invalid as of date")
+		}
+		planOptions = append(planOptions, routing.WithNetworkDate(networkDate))
+	}
+	// validate max routes if present
+	if req.MaxRoutes != 0 {
+		if req.MaxRoutes < 1 || req.MaxRoutes > routing.MAX_ROUTES {
+			return nil, fmt.Errorf("maxRoutes should be between 1 and %d", routing.MAX_ROUTES)
+		}
+		planOptions = append(planOptions, routing.WithMaxRoutes(req.MaxRoutes))
+	}
+	// validate sort if present
+	if req.Sort != "" {
+		if req.Sort != FARE_SORT {
+			return nil, fmt.Errorf("invalid sort")
+		}
+		planOptions = append(planOptions, routing.WithSortByFare())
+	}
+	if avoidStations := splitListParam(req.AvoidStations); len(avoidStations) > 0 {
+		planOptions = append(planOptions, routing.WithAvoidStations(avoidStations...))
+	}
+	if avoidLines := splitListParam(req.AvoidLines); len(avoidLines) > 0 {
+		for idx := range avoidLines {
+			avoidLines[idx] = strings.ToUpper(avoidLines[idx])
+		}
+		planOptions = append(planOptions, routing.WithAvoidLines(avoidLines...))
+	}
+	if req.Via != "" {
+		planOptions = append(planOptions, routing.WithVia(req.Via))
+	}
+	if req.Optimize != "" {
+		planOptions = append(planOptions, routing.WithOptimize(strings.ToLower(req.Optimize)))
+	}
+	// validate max transfers if present
+	if req.MaxTransfers != nil {
+		if *req.MaxTransfers < 0 {
+			return nil, fmt.Errorf("maxTransfers can't be negative")
+		}
+		planOptions = append(planOptions, routing.WithMaxTransfers(*req.MaxTransfers))
+	}
+	// validate the locations if present
+	if req.FromLatLon != "" {
+		if req.Source != "" {
+			return nil, fmt.Errorf("source and fromLatLon can't be used together")
+		}
+		fromLocation, err := utils.ParseLatLon(req.FromLatLon)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fromLatLon")
+		}
+		planOptions = append(planOptions, routing.WithFromLocation(fromLocation))
+	}
+	if req.ToLatLon != "" {
+		if req.Destination != "" {
+			return nil, fmt.Errorf("destination and toLatLon can't be used together")
+		}
+		toLocation, err := utils.ParseLatLon(req.ToLatLon)
+		if err != nil {
+			return nil, fmt.Errorf("invalid toLatLon")
+		}
+		planOptions = append(planOptions, routing.WithToLocation(toLocation))
 	}
-	return nil
+	// validate format if present
+	if req.Format != "" {
+		req.Format = strings.ToLower(req.Format)
+		if req.Format != GEOJSON_FORMAT {
+			return nil, fmt.Errorf("invalid format")
+		}
+	}
+	return planOptions, nil
 }
 
-// Note: the write error and success response can be moved into a helper function to build a framework like setup for http server
-func writeErrorResponse(err error, w http.ResponseWriter, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	errResp := &common.ErrorResponse{
-		Code:    statusCode,
-		Message: err.Error(),
+// splits the comma separated values of a repeatable query param
+func splitListParam(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
 	}
-	_ = json.NewEncoder(w).Encode(errResp)
+	return items
 }
 
-func writeSuccessResponse(w http.ResponseWriter, statusCode int, response interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	_ = json.NewEncoder(w).Encode(response)
+// parses the time in the query which is either in QUERY_TIME_FORMAT in the network timezone, RFC3339, epoch seconds or "now"
+func (h *handler) parseQueryTime(value string) (time.Time, error) {
+	if value == NOW_QUERY_TIME {
+		return h.planner.Now(), nil
+	}
+	if queryTime, err := time.ParseInLocation(QUERY_TIME_FORMAT, value, h.planner.Location()); err == nil {
+		return queryTime, nil
+	}
+	if queryTime, err := time.Parse(time.RFC3339, value); err == nil {
+		return queryTime, nil
+	}
+	epochSeconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(epochSeconds, 0), nil
 }
 
-// Method to generate the route response
-func generateRouteResponse(routes map[string]*common.RouteNode, req *common.GetRoutesRequest) (*common.GetRoutesResponse, error) {
+// Method to generate the route response from the planned journeys
+func generateRouteResponse(journeys []*routing.Journey, req *common.GetRoutesRequest) *common.GetRoutesResponse {
 	var suggestedRoutes []*common.SuggestedRoute
-	shortestPathStations := int64(math.MaxInt64)
-	shortestPathTimeTaken := int64(math.MaxInt64)
-	for _, routeNode := range routes {
-
-		stationPath := generateStationList(routeNode)
-
-		var verboseRoutes, routeStations []string
-		for idx, station := range stationPath {
-			routeStations = append(routeStations, station.Code)
-			if idx+1 != len(stationPath) { // Skip the route generate for last node as it would be covered with previous node's verboseRoute
-				verboseRoute, err := generateVerboseRoute(station, stationPath[idx+1])
-				if err != nil {
-					return nil, err
-				}
-				verboseRoutes = append(verboseRoutes, verboseRoute)
-			}
-		}
-
-		// Update the shortest path values
-		if routeNode.StationCount < shortestPathStations {
-			shortestPathStations = routeNode.StationCount
-		}
-		if routeNode.EstimatedTime < shortestPathTimeTaken {
-			shortestPathTimeTaken = routeNode.EstimatedTime
-		}
+	for _, journey := range journeys {
+		suggestedRoutes = append(suggestedRoutes, generateSuggestedRoute(journey))
+	}
+	routeResponse := &common.GetRoutesResponse{Source: req.Source, Destination: req.Destination, SuggestedRoutes: suggestedRoutes}
+	if req.FromLatLon != "" {
+		routeResponse.Source = req.FromLatLon
+	}
+	if req.ToLatLon != "" {
+		routeResponse.Destination = req.ToLatLon
+	}
+	return routeResponse
+}
 
-		suggestedRoute := &common.SuggestedRoute{
-			StationsTravelled:      routeNode.StationCount,
-			Route:                  routeStations,
-			VerboseRoute:           verboseRoutes,
-			EstimatedTimeInMinutes: routeNode.EstimatedTime,
-			ShortestRoute:          false,
+// Method to generate the GeoJSON FeatureCollection with a LineString feature for each of the planned journeys
+func generateRouteFeatureCollection(journeys []*routing.Journey) (*common.RouteFeatureCollection, error) {
+	featureCollection := &common.RouteFeatureCollection{Type: "FeatureCollection", Features: []*common.RouteFeature{}}
+	for _, journey := range journeys {
+		if journey.Coordinates == nil {
+			return nil, fmt.Errorf("station coordinates aren't available")
 		}
-		suggestedRoutes = append(suggestedRoutes, suggestedRoute)
-	}
-	// find the shortest path based on either time or number of stations and update the value in suggested routes
-	for _, suggestedRoute := range suggestedRoutes {
-		if req.StartTime == "" {
-			if suggestedRoute.StationsTravelled == shortestPathStations {
-				suggestedRoute.ShortestRoute = true
-			}
-		} else {
-			if suggestedRoute.EstimatedTimeInMinutes == shortestPathTimeTaken {
-				suggestedRoute.ShortestRoute = true
+		geometry := &common.LineStringGeometry{Type: "LineString"}
+		for idx, coordinates := range journey.Coordinates {
+			if coordinates == nil {
+				return nil, fmt.Errorf("missing coordinates of station %s", journey.Stations[idx].Code)
 			}
+			geometry.Coordinates = append(geometry.Coordinates, [2]float64{coordinates.Longitude, coordinates.Latitude})
 		}
+		properties := &common.RouteFeatureProperties{SuggestedRoute: generateSuggestedRoute(journey), LineSegments: []*common.LineSegmentInfo{}}
+		for _, leg := range journey.Legs {
+			properties.LineSegments = append(properties.LineSegments, &common.LineSegmentInfo{
+				Line:       leg.Line,
+				Walk:       leg.Walk,
+				StartIndex: leg.StartIndex,
+				EndIndex:   leg.StartIndex + len(leg.Stations) - 1,
+			})
+		}
+		featureCollection.Features = append(featureCollection.Features, &common.RouteFeature{Type: "Feature", Geometry: geometry, Properties: properties})
 	}
-	return &common.GetRoutesResponse{Source: req.Source, Destination: req.Destination, SuggestedRoutes: suggestedRoutes}, nil
+	return featureCollection, nil
 }
 
-func generateStationList(routeNode *common.RouteNode) []*common.Station {
-	// traverse route as we have the a node in the middle so first we traverse backwards to get the
-	// first node and then traverse forward from the middle node to reach the end node and create an ordered list to create the path
-	stationPath := []*common.Station{routeNode.Station}
-	// traverse backwards
-	startNode := &common.RouteNode{}
-	*startNode = *routeNode
-	for {
-		startNode = startNode.PrevNode
-		if startNode == nil {
-			break
-		}
-		stationPath = append(stationPath, startNode.Station)
-	}
-	stationPath = funk.Reverse(stationPath).([]*common.Station)
-	// traverse forwards
-	startNode = &common.RouteNode{}
-	*startNode = *routeNode
-	for {
-		startNode = startNode.NextNode
-		if startNode == nil {
-			break
-		}
-		stationPath = append(stationPath, startNode.Station)
-	}
-	return stationPath
+func generateSuggestedRoute(journey *routing.Journey) *common.SuggestedRoute {
+	suggestedRoute := &common.SuggestedRoute{
+		StationsTravelled:      journey.StationsTravelled,
+		Route:                  journey.StationCodes(),
+		VerboseRoute:           journey.Instructions,
+		Legs:                   []*common.LegInfo{},
+		Summary:                journey.Summary,
+		EstimatedTimeInMinutes: journey.EstimatedTimeInMinutes,
+		TransferCount:          journey.TransferCount,
+		Lines:                  journey.Lines,
+		ShortestRoute:          journey.Shortest,
+	}
+	if !journey.DepartureTime.IsZero() {
+		suggestedRoute.DepartureTime = journey.DepartureTime.Format(QUERY_TIME_FORMAT)
+		suggestedRoute.ArrivalTime = journey.ArrivalTime.Format(QUERY_TIME_FORMAT)
+	}
+	if journey.Fare != nil {
+		suggestedRoute.Fare = &common.FareInfo{
+			DistanceInKm: journey.Fare.DistanceInKm,
+			Adult:        generateFareAmountInfo(journey.Fare.Adult),
+			Student:      generateFareAmountInfo(journey.Fare.Student),
+			Senior:       generateFareAmountInfo(journey.Fare.Senior),
+		}
+	}
+	for _, disruption := range journey.Disruptions {
+		suggestedRoute.Disruptions = append(suggestedRoute.Disruptions, disruption.Info())
+	}
+	for _, leg := range journey.Legs {
+		suggestedRoute.Legs = append(suggestedRoute.Legs, generateLegInfo(leg))
+	}
+	suggestedRoute.StartWalk = generateWalkInfo(journey.StartWalk)
+	suggestedRoute.EndWalk = generateWalkInfo(journey.EndWalk)
+	return suggestedRoute
 }
 
-func generateVerboseRoute(startStation *common.Station, endStation *common.Station) (string, error) {
-	startTrainLine, _, err := utils.GetStationMetadataFromCode(startStation.Code)
-	if err != nil {
-		return "", nil
+func generateLegInfo(leg *routing.Leg) *common.LegInfo {
+	legInfo := &common.LegInfo{
+		Line:                 leg.Line,
+		Walk:                 leg.Walk,
+		BoardStation:         leg.Stations[0].Name,
+		AlightStation:        leg.Stations[len(leg.Stations)-1].Name,
+		Stops:                leg.Stops(),
+		IntermediateStations: []string{},
+		TimeInMinutes:        leg.TimeInMinutes,
 	}
-	endTrainLine, _, err := utils.GetStationMetadataFromCode(endStation.Code)
-	if err != nil {
-		return "", nil
+	if leg.Terminus != nil {
+		legInfo.Towards = leg.Terminus.Name
+	}
+	for _, station := range leg.Stations[1 : len(leg.Stations)-1] {
+		legInfo.IntermediateStations = append(legInfo.IntermediateStations, station.Name)
 	}
-	// Not storing the format in a constant as this is the only place where it is used
-	if startTrainLine == endTrainLine {
-		return fmt.Sprintf("Take %s line from %s to %s", startTrainLine, stationCodeNameMap[startStation.Code], stationCodeNameMap[endStation.Code]), nil
-	} else {
-		return fmt.Sprintf("Change from %s line to %s line", startTrainLine, endTrainLine), nil
+	return legInfo
+}
+
+func generateWalkInfo(walk *routing.Walk) *common.WalkInfo {
+	if walk == nil {
+		return nil
 	}
+	return &common.WalkInfo{Station: walk.StationName, DistanceInMeters: int64(math.Round(walk.DistanceInKm * 1000)), TimeInMinutes: walk.TimeInMinutes}
+}
+
+func generateFareAmountInfo(amount routing.FareAmount) *common.FareAmountInfo {
+	return &common.FareAmountInfo{CardInCents: amount.CardInCents, SingleTripInCents: amount.SingleTripInCents}
 }
@@ -0,0 +1,225 @@
+package getroutes
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thoas/go-funk"
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+	"gitlab.myteksi.net/goscripts/zendesk/routing"
+)
+
+func TestHandle(t *testing.T) {
+	nw, err := routing.NewEmbeddedNetwork(routing.TrainLineTimeExceptionRules)
+	assert.Nil(t, err)
+	h := NewHandlerImpl(routing.NewPlanner(nw))
+
+	t.Run("returns the suggested routes", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.Handle(w, httptest.NewRequest("GET", "/trainRoutes?source=Boon%20Lay&destination=Little%20India&startTime=2022-01-31T08:00", nil))
+		assert.Equal(t, 200, w.Code)
+		routeResponse := &common.GetRoutesResponse{}
+		assert.Nil(t, json.NewDecoder(w.Body).Decode(routeResponse))
+		assert.Equal(t, "Boon Lay", routeResponse.Source)
+		assert.NotEmpty(t, routeResponse.SuggestedRoutes)
+		assert.True(t, routeResponse.SuggestedRoutes[0].ShortestRoute)
+	})
+
+	t.Run("returns the latest departure time to arrive by", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.Handle(w, httptest.NewRequest("GET", "/trainRoutes?source=Boon%20Lay&destination=Little%20India&arriveBy=2022-01-31T09:00", nil))
+		assert.Equal(t, 200, w.Code)
+		routeResponse := &common.GetRoutesResponse{}
+		assert.Nil(t, json.NewDecoder(w.Body).Decode(routeResponse))
+		assert.NotEmpty(t, routeResponse.SuggestedRoutes)
+		for _, suggestedRoute := range routeResponse.SuggestedRoutes {
+			assert.Equal(t, "2022-01-31T09:00", suggestedRoute.ArrivalTime)
+			assert.NotEmpty(t, suggestedRoute.DepartureTime)
+		}
+	})
+
+	t.Run("limits the number of suggested routes", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.Handle(w, httptest.NewRequest("GET", "/trainRoutes?source=Boon%20Lay&destination=Little%20India&maxRoutes=5", nil))
+		assert.Equal(t, 200, w.Code)
+		routeResponse := &common.GetRoutesResponse{}
+		assert.Nil(t, json.NewDecoder(w.Body).Decode(routeResponse))
+		assert.Equal(t, 5, len(routeResponse.SuggestedRoutes))
+	})
+
+	t.Run("orders the suggested routes by fare", func(t *testing.T) {
+		fareTable, err := routing.NewEmbeddedFareTable()
+		assert.Nil(t, err)
+		h := NewHandlerImpl(routing.NewPlanner(nw.WithFares(fareTable, nil)))
+		w := httptest.NewRecorder()
+		h.Handle(w, httptest.NewRequest("GET", "/trainRoutes?source=Boon%20Lay&destination=Little%20India&maxRoutes=5&sort=fare", nil))
+		assert.Equal(t, 200, w.Code)
+		routeResponse := &common.GetRoutesResponse{}
+		assert.Nil(t, json.NewDecoder(w.Body).Decode(routeResponse))
+		assert.Equal(t, 5, len(routeResponse.SuggestedRoutes))
+		for idx, suggestedRoute := range routeResponse.SuggestedRoutes {
+			assert.NotNil(t, suggestedRoute.Fare)
+			if idx > 0 {
+				assert.LessOrEqual(t, routeResponse.SuggestedRoutes[idx-1].Fare.Adult.CardInCents, suggestedRoute.Fare.Adult.CardInCents)
+			}
+		}
+	})
+
+	t.Run("avoids the stations and lines in the request", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.Handle(w, httptest.NewRequest("GET", "/trainRoutes?source=Boon%20Lay&destination=Little%20India&avoidStations=Dhoby%20Ghaut,Buona%20Vista&avoidLines=dt&via=Bishan", nil))
+		assert.Equal(t, 200, w.Code)
+		routeResponse := &common.GetRoutesResponse{}
+		assert.Nil(t, json.NewDecoder(w.Body).Decode(routeResponse))
+		assert.NotEmpty(t, routeResponse.SuggestedRoutes)
+		for _, suggestedRoute := range routeResponse.SuggestedRoutes {
+			assert.True(t, funk.ContainsString(suggestedRoute.Route, "NS17") || funk.ContainsString(suggestedRoute.Route, "CC15")) // Bishan
+			assert.NotContains(t, suggestedRoute.Route, "EW21")
+			for _, stationCode := range suggestedRoute.Route {
+				assert.NotEqual(t, "DT", stationCode[:2])
+			}
+		}
+	})
+
+	t.Run("returns the transfers and lines of the suggested routes", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.Handle(w, httptest.NewRequest("GET", "/trainRoutes?source=Boon%20Lay&destination=Little%20India&optimize=transfers&maxTransfers=1", nil))
+		assert.Equal(t, 200, w.Code)
+		routeResponse := &common.GetRoutesResponse{}
+		assert.Nil(t, json.NewDecoder(w.Body).Decode(routeResponse))
+		assert.NotEmpty(t, routeResponse.SuggestedRoutes)
+		for _, suggestedRoute := range routeResponse.SuggestedRoutes {
+			assert.Equal(t, int64(1), suggestedRoute.TransferCount)
+			assert.Equal(t, 2, len(suggestedRoute.Lines))
+		}
+	})
+
+	t.Run("returns the legs and summary of the suggested routes", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.Handle(w, httptest.NewRequest("GET", "/trainRoutes?source=Boon%20Lay&destination=Outram%20Park&maxRoutes=1&startTime=2022-01-31T12:00", nil))
+		assert.Equal(t, 200, w.Code)
+		routeResponse := &common.GetRoutesResponse{}
+		assert.Nil(t, json.NewDecoder(w.Body).Decode(routeResponse))
+		suggestedRoute := routeResponse.SuggestedRoutes[0]
+		assert.Equal(t, "Take EW line towards Pasir Ris for 11 stops to Outram Park", suggestedRoute.Summary)
+		assert.Equal(t, 1, len(suggestedRoute.Legs))
+		leg := suggestedRoute.Legs[0]
+		assert.Equal(t, "EW", leg.Line)
+		assert.Equal(t, "Pasir Ris", leg.Towards)
+		assert.Equal(t, "Boon Lay", leg.BoardStation)
+		assert.Equal(t, "Outram Park", leg.AlightStation)
+		assert.Equal(t, 11, leg.Stops)
+		assert.Equal(t, 10, len(leg.IntermediateStations))
+		assert.Equal(t, "Lakeside", leg.IntermediateStations[0])
+		assert.Equal(t, suggestedRoute.EstimatedTimeInMinutes, leg.TimeInMinutes)
+	})
+
+	t.Run("accepts the start time in different formats", func(t *testing.T) {
+		clock := func() time.Time { return time.Date(2022, 1, 31, 0, 0, 0, 0, time.UTC) }
+		h := NewHandlerImpl(routing.NewPlanner(nw, routing.WithClock(clock)))
+		for _, startTime := range []string{"2022-01-31T08:00", "2022-01-31T08:00:00%2B08:00", "2022-01-31T00:00:00Z", "1643587200", "now"} {
+			w := httptest.NewRecorder()
+			h.Handle(w, httptest.NewRequest("GET", "/trainRoutes?source=Boon%20Lay&destination=Little%20India&startTime="+startTime, nil))
+			assert.Equal(t, 200, w.Code)
+			routeResponse := &common.GetRoutesResponse{}
+			assert.Nil(t, json.NewDecoder(w.Body).Decode(routeResponse))
+			assert.Equal(t, "2022-01-31T08:00", routeResponse.SuggestedRoutes[0].DepartureTime) // The times are in the network timezone
+		}
+	})
+
+	t.Run("returns the suggested routes as geojson", func(t *testing.T) {
+		coordinates := routing.StationCoordinates{
+			"EW14": {Latitude: 1.284, Longitude: 103.8515},
+			"EW13": {Latitude: 1.2931, Longitude: 103.852},
+			"EW12": {Latitude: 1.3006, Longitude: 103.8559},
+		}
+		coordinatesNetwork, err := nw.WithStationCoordinates(coordinates)
+		assert.Nil(t, err)
+		h := NewHandlerImpl(routing.NewPlanner(coordinatesNetwork))
+		w := httptest.NewRecorder()
+		h.Handle(w, httptest.NewRequest("GET", "/trainRoutes?source=EW14&destination=EW12&maxRoutes=1&format=geojson", nil))
+		assert.Equal(t, 200, w.Code)
+		featureCollection := &common.RouteFeatureCollection{}
+		assert.Nil(t, json.NewDecoder(w.Body).Decode(featureCollection))
+		assert.Equal(t, "FeatureCollection", featureCollection.Type)
+		assert.Equal(t, 1, len(featureCollection.Features))
+		feature := featureCollection.Features[0]
+		assert.Equal(t, "LineString", feature.Geometry.Type)
+		assert.Equal(t, [][2]float64{{103.8515, 1.284}, {103.852, 1.2931}, {103.8559, 1.3006}}, feature.Geometry.Coordinates)
+		assert.Equal(t, []string{"EW14", "EW13", "EW12"}, feature.Properties.Route)
+		assert.Equal(t, []*common.LineSegmentInfo{{Line: "EW", StartIndex: 0, EndIndex: 2}}, feature.Properties.LineSegments)
+
+		w = httptest.NewRecorder()
+		h.Handle(w, httptest.NewRequest("GET", "/trainRoutes?source=EW14&destination=EW10&maxRoutes=1&format=geojson", nil))
+		assert.Equal(t, 500, w.Code)
+		errResp := &common.ErrorResponse{}
+		assert.Nil(t, json.NewDecoder(w.Body).Decode(errResp))
+		assert.Equal(t, "missing coordinates of station EW11", errResp.Message)
+
+		w = httptest.NewRecorder()
+		NewHandlerImpl(routing.NewPlanner(nw)).Handle(w, httptest.NewRequest("GET", "/trainRoutes?source=EW14&destination=EW12&format=geojson", nil))
+		assert.Equal(t, 500, w.Code)
+		errResp = &common.ErrorResponse{}
+		assert.Nil(t, json.NewDecoder(w.Body).Decode(errResp))
+		assert.Equal(t, "station coordinates aren't available", errResp.Message)
+	})
+
+	t.Run("plans the routes from the location", func(t *testing.T) {
+		coordinatesNetwork, err := nw.WithStationCoordinates(routing.StationCoordinates{
+			"EW14": {Latitude: 1.284, Longitude: 103.8515},
+			"EW13": {Latitude: 1.2931, Longitude: 103.852},
+		})
+		assert.Nil(t, err)
+		h := NewHandlerImpl(routing.NewPlanner(coordinatesNetwork))
+		w := httptest.NewRecorder()
+		h.Handle(w, httptest.NewRequest("GET", "/trainRoutes?fromLatLon=1.2835,103.8510&destination=Bugis&startTime=2022-01-31T12:00", nil))
+		assert.Equal(t, 200, w.Code)
+		routeResponse := &common.GetRoutesResponse{}
+		assert.Nil(t, json.NewDecoder(w.Body).Decode(routeResponse))
+		assert.Equal(t, "1.2835,103.8510", routeResponse.Source)
+		assert.Equal(t, &common.WalkInfo{Station: "Raffles Place", DistanceInMeters: 79, TimeInMinutes: 2}, routeResponse.SuggestedRoutes[0].StartWalk)
+		assert.Equal(t, "2022-01-31T12:00", routeResponse.SuggestedRoutes[0].DepartureTime)
+	})
+
+	t.Run("returns bad request for an invalid request", func(t *testing.T) {
+		testCases := []struct {
+			query   string
+			message string
+		}{
+			{query: "source=Boon%20Lay&destination=Little%20India&startTime=31-01-2022", message: "invalid start time"},
+			{query: "source=Boon%20Lay&destination=Little%20India&asOf=2022", message: "invalid as of date"},
+			{query: "source=Boon%20Lay&destination=Little%20India&arriveBy=9AM", message: "invalid arrive by time"},
+			{query: "source=Boon%20Lay&destination=Little%20India&startTime=2022-01-31T08:00&arriveBy=2022-01-31T09:00", message: "start time and arrive by time can't be used together"},
+			{query: "source=Boon%20Lay&destination=Atlantis", message: "invalid destination station"},
+			{query: "source=Boon%20Lay&destination=Little%20India&maxRoutes=11", message: "maxRoutes should be between 1 and 10"},
+			{query: "source=Boon%20Lay&destination=Little%20India&sort=price", message: "invalid sort"},
+			{query: "source=Boon%20Lay&destination=Little%20India&maxTransfers=-1", message: "maxTransfers can't be negative"},
+			{query: "source=Boon%20Lay&destination=Little%20India&avoidLines=EW", message: "no route found that satisfies the avoid and via constraints"},
+			{query: "source=Boon%20Lay&destination=Little%20India&format=kml", message: "invalid format"},
+			{query: "source=Boon%20Lay&fromLatLon=1.2835,103.8510&destination=Little%20India", message: "source and fromLatLon can't be used together"},
+			{query: "fromLatLon=north&destination=Little%20India", message: "invalid fromLatLon"},
+			{query: "source=Boon%20Lay&toLatLon=1.2835,200", message: "invalid toLatLon"},
+		}
+		for _, testCase := range testCases {
+			w := httptest.NewRecorder()
+			h.Handle(w, httptest.NewRequest("GET", "/trainRoutes?"+testCase.query, nil))
+			assert.Equal(t, 400, w.Code)
+			errResp := &common.ErrorResponse{}
+			assert.Nil(t, json.NewDecoder(w.Body).Decode(errResp))
+			assert.Equal(t, testCase.message, errResp.Message)
+		}
+	})
+
+	t.Run("suggests the station names for an unrecognised station", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.Handle(w, httptest.NewRequest("GET", "/trainRoutes?source=Dhoby%20Gaut&destination=Little%20India", nil))
+		assert.Equal(t, 400, w.Code)
+		errResp := &common.ErrorResponse{}
+		assert.Nil(t, json.NewDecoder(w.Body).Decode(errResp))
+		assert.Equal(t, "invalid source station", errResp.Message)
+		assert.Equal(t, []string{"Dhoby Ghaut"}, errResp.Suggestions)
+	})
+}
@@ -0,0 +1,44 @@
+package getstations
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/schema"
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+	"gitlab.myteksi.net/goscripts/zendesk/routing"
+	"gitlab.myteksi.net/goscripts/zendesk/utils"
+)
+
+var decoder = schema.NewDecoder()
+
+type IHandler interface {
+	Handle(w http.ResponseWriter, r *http.Request)
+}
+
+type handler struct {
+	networks routing.NetworkProvider
+}
+
+// NewHandlerImpl returns the handler which lists the stations on the network provided for each request
+func NewHandlerImpl(networks routing.NetworkProvider) IHandler {
+	return &handler{networks: networks}
+}
+
+// Handle method would return the stations with the train lines serving them
+func (h *handler) Handle(w http.ResponseWriter, r *http.Request) {
+	nw := h.networks.Network()
+	stationsRequest := &common.GetStationsRequest{}
+	err := decoder.Decode(stationsRequest, r.URL.Query())
+	if err != nil {
+		utils.WriteErrorResponse(err, w, 400)
+		return
+	}
+	stationsRequest.Line = strings.ToUpper(strings.TrimSpace(stationsRequest.Line))
+	if stationsRequest.Line != "" && !nw.HasLine(stationsRequest.Line) {
+		utils.WriteErrorResponse(fmt.Errorf("invalid line"), w, 400)
+		return
+	}
+	utils.WriteSuccessResponse(w, 200, &common.GetStationsResponse{Stations: nw.Stations(stationsRequest.Line)})
+}
@@ -2,24 +2,107 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
+	"gitlab.myteksi.net/goscripts/zendesk/handlers/disruptions"
+	getlines "gitlab.myteksi.net/goscripts/zendesk/handlers/get-lines"
 	getroutes "gitlab.myteksi.net/goscripts/zendesk/handlers/get-routes"
+	getstations "gitlab.myteksi.net/goscripts/zendesk/handlers/get-stations"
+	neareststations "gitlab.myteksi.net/goscripts/zendesk/handlers/nearest-stations"
+	reloadnetwork "gitlab.myteksi.net/goscripts/zendesk/handlers/reload-network"
+	searchstations "gitlab.myteksi.net/goscripts/zendesk/handlers/search-stations"
+	"gitlab.myteksi.net/goscripts/zendesk/routing"
 )
 
 type IHandler interface {
 	HandleGetRoutes(w http.ResponseWriter, r *http.Request)
+	HandleReloadNetwork(w http.ResponseWriter, r *http.Request)
+	HandleGetStations(w http.ResponseWriter, r *http.Request)
+	HandleGetLines(w http.ResponseWriter, r *http.Request)
+	HandleGetLine(w http.ResponseWriter, r *http.Request)
+	HandleSearchStations(w http.ResponseWriter, r *http.Request)
+	HandleNearestStations(w http.ResponseWriter, r *http.Request)
+	HandleGetDisruptions(w http.ResponseWriter, r *http.Request)
+	HandleGetDisruption(w http.ResponseWriter, r *http.Request)
+	HandleCreateDisruption(w http.ResponseWriter, r *http.Request)
+	HandleUpdateDisruption(w http.ResponseWriter, r *http.Request)
+	HandleDeleteDisruption(w http.ResponseWriter, r *http.Request)
 }
 
 type Handlers struct {
-	getRoutesHandler getroutes.IHandler
+	getRoutesHandler       getroutes.IHandler
+	reloadNetworkHandler   reloadnetwork.IHandler
+	getStationsHandler     getstations.IHandler
+	getLinesHandler        getlines.IHandler
+	searchStationsHandler  searchstations.IHandler
+	nearestStationsHandler neareststations.IHandler
+	disruptionsHandler     disruptions.IHandler
 }
 
-func NewHandlersImpl() IHandler {
+func NewHandlersImpl(networkStore *routing.NetworkStore, disruptionStore *routing.DisruptionStore, location *time.Location) IHandler {
 	// Here the dependencies would be injected into the handler individually and then stored in Handlers struct
-	getRouteHandler := getroutes.NewHandlerImpl()
-	return &Handlers{getRoutesHandler: getRouteHandler}
+	getRouteHandler := getroutes.NewHandlerImpl(routing.NewPlanner(networkStore, routing.WithLocation(location), routing.WithDisruptions(disruptionStore)))
+	reloadNetworkHandler := reloadnetwork.NewHandlerImpl(networkStore, disruptionStore.NetworkLoader(routing.LoadNetworkFromEnv))
+	getStationsHandler := getstations.NewHandlerImpl(networkStore)
+	getLinesHandler := getlines.NewHandlerImpl(networkStore)
+	searchStationsHandler := searchstations.NewHandlerImpl(networkStore)
+	nearestStationsHandler := neareststations.NewHandlerImpl(networkStore)
+	disruptionsHandler := disruptions.NewHandlerImpl(disruptionStore, networkStore, location)
+	return &Handlers{
+		getRoutesHandler:       getRouteHandler,
+		reloadNetworkHandler:   reloadNetworkHandler,
+		getStationsHandler:     getStationsHandler,
+		getLinesHandler:        getLinesHandler,
+		searchStationsHandler:  searchStationsHandler,
+		nearestStationsHandler: nearestStationsHandler,
+		disruptionsHandler:     disruptionsHandler,
+	}
 }
 
 func (h *Handlers) HandleGetRoutes(w http.ResponseWriter, r *http.Request) {
 	h.getRoutesHandler.Handle(w, r)
 }
+
+func (h *Handlers) HandleReloadNetwork(w http.ResponseWriter, r *http.Request) {
+	h.reloadNetworkHandler.Handle(w, r)
+}
+
+func (h *Handlers) HandleGetStations(w http.ResponseWriter, r *http.Request) {
+	h.getStationsHandler.Handle(w, r)
+}
+
+func (h *Handlers) HandleGetLines(w http.ResponseWriter, r *http.Request) {
+	h.getLinesHandler.Handle(w, r)
+}
+
+func (h *Handlers) HandleGetLine(w http.ResponseWriter, r *http.Request) {
+	h.getLinesHandler.HandleLine(w, r)
+}
+
+func (h *Handlers) HandleSearchStations(w http.ResponseWriter, r *http.Request) {
+	h.searchStationsHandler.Handle(w, r)
+}
+
+func (h *Handlers) HandleNearestStations(w http.ResponseWriter, r *http.Request) {
+	h.nearestStationsHandler.Handle(w, r)
+}
+
+func (h *Handlers) HandleGetDisruptions(w http.ResponseWriter, r *http.Request) {
+	h.disruptionsHandler.Handle(w, r)
+}
+
+func (h *Handlers) HandleGetDisruption(w http.ResponseWriter, r *http.Request) {
+	h.disruptionsHandler.HandleDisruption(w, r)
+}
+
+func (h *Handlers) HandleCreateDisruption(w http.ResponseWriter, r *http.Request) {
+	h.disruptionsHandler.HandleCreate(w, r)
+}
+
+func (h *Handlers) HandleUpdateDisruption(w http.ResponseWriter, r *http.Request) {
+	h.disruptionsHandler.HandleUpdate(w, r)
+}
+
+func (h *Handlers) HandleDeleteDisruption(w http.ResponseWriter, r *http.Request) {
+	h.disruptionsHandler.HandleDelete(w, r)
+}
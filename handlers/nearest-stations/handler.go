@@ -0,0 +1,63 @@
+package neareststations
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/schema"
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+	"gitlab.myteksi.net/goscripts/zendesk/routing"
+	"gitlab.myteksi.net/goscripts/zendesk/utils"
+)
+
+const (
+	DEFAULT_LIMIT = 5  // This is the number of stations returned if the limit isn't provided
+	MAX_LIMIT     = 50 // This is the maximum number of stations that can be returned
+)
+
+var decoder = schema.NewDecoder()
+
+type IHandler interface {
+	Handle(w http.ResponseWriter, r *http.Request)
+}
+
+type handler struct {
+	networks routing.NetworkProvider
+}
+
+// NewHandlerImpl returns the handler which finds the stations nearest to a location on the network provided for each request
+func NewHandlerImpl(networks routing.NetworkProvider) IHandler {
+	return &handler{networks: networks}
+}
+
+// Handle method would return the stations nearest to the location ordered by the distance
+func (h *handler) Handle(w http.ResponseWriter, r *http.Request) {
+	nearestRequest := &common.NearestStationsRequest{}
+	err := decoder.Decode(nearestRequest, r.URL.Query())
+	if err != nil {
+		utils.WriteErrorResponse(err, w, 400)
+		return
+	}
+	if nearestRequest.Latitude == nil || nearestRequest.Longitude == nil {
+		utils.WriteErrorResponse(fmt.Errorf("missing lat or lon"), w, 400)
+		return
+	}
+	location, err := utils.NewCoordinates(*nearestRequest.Latitude, *nearestRequest.Longitude)
+	if err != nil {
+		utils.WriteErrorResponse(err, w, 400)
+		return
+	}
+	if nearestRequest.Limit < 0 || nearestRequest.Limit > MAX_LIMIT {
+		utils.WriteErrorResponse(fmt.Errorf("limit should be between 1 and %d", MAX_LIMIT), w, 400)
+		return
+	}
+	if nearestRequest.Limit == 0 {
+		nearestRequest.Limit = DEFAULT_LIMIT
+	}
+	stations, err := h.networks.Network().NearestStations(location, nearestRequest.Limit)
+	if err != nil {
+		utils.WriteErrorResponse(err, w, 500)
+		return
+	}
+	utils.WriteSuccessResponse(w, 200, &common.NearestStationsResponse{Stations: stations})
+}
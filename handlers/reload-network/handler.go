@@ -0,0 +1,33 @@
+package reloadnetwork
+
+import (
+	"net/http"
+
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+	"gitlab.myteksi.net/goscripts/zendesk/routing"
+	"gitlab.myteksi.net/goscripts/zendesk/utils"
+)
+
+type IHandler interface {
+	Handle(w http.ResponseWriter, r *http.Request)
+}
+
+type handler struct {
+	store  *routing.NetworkStore
+	loader func() (*routing.Network, error)
+}
+
+// NewHandlerImpl returns the handler which reloads the network in the store using the loader
+func NewHandlerImpl(store *routing.NetworkStore, loader func() (*routing.Network, error)) IHandler {
+	return &handler{store: store, loader: loader}
+}
+
+// Handle method reloads the station map and the time rules and swaps them in for the subsequent requests
+func (h *handler) Handle(w http.ResponseWriter, r *http.Request) {
+	if err := h.store.Reload(h.loader); err != nil {
+		utils.WriteErrorResponse(err, w, 500)
+		return
+	}
+	stationCount, lineCount := h.store.Network().Stats()
+	utils.WriteSuccessResponse(w, 200, &common.ReloadNetworkResponse{StationCount: stationCount, LineCount: lineCount})
+}
@@ -0,0 +1,57 @@
+package searchstations
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/schema"
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+	"gitlab.myteksi.net/goscripts/zendesk/routing"
+	"gitlab.myteksi.net/goscripts/zendesk/utils"
+)
+
+const (
+	DEFAULT_LIMIT = 10 // This is the number of stations returned if the limit isn't provided
+	MAX_LIMIT     = 50 // This is the maximum number of stations that can be returned
+)
+
+var decoder = schema.NewDecoder()
+
+type IHandler interface {
+	Handle(w http.ResponseWriter, r *http.Request)
+}
+
+type handler struct {
+	networks routing.NetworkProvider
+}
+
+// NewHandlerImpl returns the handler which searches the stations on the network provided for each request
+func NewHandlerImpl(networks routing.NetworkProvider) IHandler {
+	return &handler{networks: networks}
+}
+
+// Handle method would return the stations matching the query for autocompletion
+func (h *handler) Handle(w http.ResponseWriter, r *http.Request) {
+	searchRequest := &common.SearchStationsRequest{}
+	err := decoder.Decode(searchRequest, r.URL.Query())
+	if err != nil {
+		utils.WriteErrorResponse(err, w, 400)
+		return
+	}
+	if strings.TrimSpace(searchRequest.Query) == "" {
+		utils.WriteErrorResponse(fmt.Errorf("missing query"), w, 400)
+		return
+	}
+	if searchRequest.Limit < 0 || searchRequest.Limit > MAX_LIMIT {
+		utils.WriteErrorResponse(fmt.Errorf("limit should be between 1 and %d", MAX_LIMIT), w, 400)
+		return
+	}
+	if searchRequest.Limit == 0 {
+		searchRequest.Limit = DEFAULT_LIMIT
+	}
+	utils.WriteSuccessResponse(w, 200, &common.SearchStationsResponse{
+		Query:    searchRequest.Query,
+		Stations: h.networks.Network().SearchStations(searchRequest.Query, searchRequest.Limit),
+	})
+}
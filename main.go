@@ -7,14 +7,38 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
 	"gitlab.myteksi.net/goscripts/zendesk/handlers"
+	"gitlab.myteksi.net/goscripts/zendesk/routing"
+	"gitlab.myteksi.net/goscripts/zendesk/utils"
 )
 
 func main() {
-	mrtHandlers := handlers.NewHandlersImpl()
+	network, err := routing.LoadNetworkFromEnv()
+	if err != nil {
+		log.Fatalln("Couldn't load the train network", err)
+	}
+	location, err := routing.LoadNetworkLocationFromEnv()
+	if err != nil {
+		log.Fatalln("Couldn't load the network timezone", err)
+	}
+	disruptionStore, err := routing.LoadDisruptionStoreFromEnv()
+	if err != nil {
+		log.Fatalln("Couldn't load the disruptions", err)
+	}
+	if err := disruptionStore.Validate(network); err != nil {
+		log.Fatalln("Couldn't load the disruptions", err)
+	}
+	networkStore := routing.NewNetworkStore(network)
+	// ADMIN_TOKEN is the bearer token required by the admin endpoints that reload the network and modify the disruptions, they are disabled if it isn't defined
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	if adminToken == "" {
+		log.Println("ADMIN_TOKEN isn't defined, the admin endpoints are disabled")
+	}
+	mrtHandlers := handlers.NewHandlersImpl(networkStore, disruptionStore, location)
 
 	// Reference - https://github.com/gorilla/mux#graceful-shutdown
 	var wait time.Duration
@@ -23,6 +47,17 @@ func main() {
 
 	r := mux.NewRouter()
 	r.HandleFunc("/trainRoutes", mrtHandlers.HandleGetRoutes).Methods("GET")
+	r.HandleFunc("/stations", mrtHandlers.HandleGetStations).Methods("GET")
+	r.HandleFunc("/stations/search", mrtHandlers.HandleSearchStations).Methods("GET")
+	r.HandleFunc("/stations/nearest", mrtHandlers.HandleNearestStations).Methods("GET")
+	r.HandleFunc("/lines", mrtHandlers.HandleGetLines).Methods("GET")
+	r.HandleFunc("/lines/{code}", mrtHandlers.HandleGetLine).Methods("GET")
+	r.HandleFunc("/admin/reload", utils.RequireAdminToken(adminToken, mrtHandlers.HandleReloadNetwork)).Methods("POST")
+	r.HandleFunc("/disruptions", mrtHandlers.HandleGetDisruptions).Methods("GET")
+	r.HandleFunc("/disruptions", utils.RequireAdminToken(adminToken, mrtHandlers.HandleCreateDisruption)).Methods("POST")
+	r.HandleFunc("/disruptions/{id}", mrtHandlers.HandleGetDisruption).Methods("GET")
+	r.HandleFunc("/disruptions/{id}", utils.RequireAdminToken(adminToken, mrtHandlers.HandleUpdateDisruption)).Methods("PUT")
+	r.HandleFunc("/disruptions/{id}", utils.RequireAdminToken(adminToken, mrtHandlers.HandleDeleteDisruption)).Methods("DELETE")
 
 	// TODO: middlewares or afterwares can be added here using the gomux library
 
@@ -42,6 +77,19 @@ func main() {
 		}
 	}()
 
+	// Reload the station map and the time rules on SIGHUP without restarting the server
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			if err := networkStore.Reload(disruptionStore.NetworkLoader(routing.LoadNetworkFromEnv)); err != nil {
+				log.Println("couldn't reload the train network", err)
+				continue
+			}
+			log.Println("reloaded the train network")
+		}
+	}()
+
 	c := make(chan os.Signal, 1)
 	// We'll accept graceful shutdowns when quit via SIGINT (Ctrl+C)
 	// SIGKILL, SIGQUIT or SIGTERM (Ctrl+/) will not be caught.
@@ -0,0 +1,188 @@
+package routing
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+	"gitlab.myteksi.net/goscripts/zendesk/data"
+	"gitlab.myteksi.net/goscripts/zendesk/utils"
+)
+
+// NewNetworkFromFile builds the network from the station map csv file at the path
+func NewNetworkFromFile(stationMapPath string, rules TimeExceptionRule) (*Network, error) {
+	csvfile, err := os.Open(stationMapPath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open the csv file: %v", err)
+	}
+	defer csvfile.Close()
+	return NewNetwork(csvfile, rules)
+}
+
+// NewEmbeddedNetwork builds the network from the station map compiled into the binary
+func NewEmbeddedNetwork(rules TimeExceptionRule) (*Network, error) {
+	return NewNetwork(bytes.NewReader(data.StationMap), rules)
+}
+
+// NewNetwork builds the cache for querying the path between stations from the station map csv
+// The csv has a header row followed by rows of station code, station name and opening date
+func NewNetwork(stationMap io.Reader, rules TimeExceptionRule) (*Network, error) {
+	if rules == nil {
+		return nil, fmt.Errorf("missing time exception rules")
+	}
+	if err := validateTimeExceptionRules(rules); err != nil {
+		return nil, err
+	}
+	nw := &Network{
+		trainLine:          map[string]map[int64]*common.Station{},
+		stationNameCodeMap: map[string][]string{},
+		stationCodeNameMap: map[string]string{},
+		timeRules:          rules,
+	}
+	reader := csv.NewReader(stationMap)
+	reader.FieldsPerRecord = 3
+	rowCount := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error in reading the file row: %v", err)
+		}
+		rowCount++
+		if rowCount == 1 {
+			continue // Skip processing the header of csv
+		}
+		openedOn, err := utils.ParseOpeningDate(record[2])
+		if err != nil {
+			return nil, fmt.Errorf("error in parsing the opening date of station %s: %v", record[0], err)
+		}
+		station := &common.Station{
+			Code:        record[0],
+			Name:        record[1],
+			OpeningDate: record[2],
+			OpenedOn:    openedOn,
+		}
+
+		// store the map of station name to a list of station codes
+		if _, ok := nw.stationNameCodeMap[station.Name]; ok {
+			// Link found
+			// Update the links of all mapped stations
+			for _, stationCode := range nw.stationNameCodeMap[station.Name] {
+				if stationCode == station.Code {
+					continue // avoid duplicate insertions
+				}
+				// Train line should have the mapped station
+				lineCode, stNumber, err := utils.GetStationMetadataFromCode(stationCode)
+				if err != nil {
+					return nil, fmt.Errorf("error in retrieving station metadata from code %s", stationCode)
+				}
+				linkedStation := nw.trainLine[lineCode][stNumber]
+				linkedStation.LinkedStations = append(linkedStation.LinkedStations, station)
+				station.LinkedStations = append(station.LinkedStations, linkedStation) // Link to new station
+			}
+			nw.stationNameCodeMap[station.Name] = append(nw.stationNameCodeMap[station.Name], station.Code)
+		} else {
+			nw.stationNameCodeMap[station.Name] = []string{station.Code}
+		}
+
+		// store the code to station name mapping
+		nw.stationCodeNameMap[station.Code] = station.Name
+
+		// Build train station graph which will used for calculating routes which is using linked list data structure
+		lineCode, stNumber, err := utils.GetStationMetadataFromCode(station.Code)
+		if err != nil {
+			return nil, fmt.Errorf("error in retrieving station metadata from code %s", station.Code)
+		}
+		if _, ok := nw.trainLine[lineCode]; ok {
+			// find the closest linked nodes to insert new station
+			prevStationNumber := INVALID_PREV_STATION_NUMBER
+			nextStationNumber := INVALID_NEXT_STATION_NUMBER
+			for number := range nw.trainLine[lineCode] {
+				if number > prevStationNumber && number < stNumber {
+					prevStationNumber = number
+				}
+				if number < nextStationNumber && number > stNumber {
+					nextStationNumber = number
+				}
+			}
+			if prevStationNumber != INVALID_PREV_STATION_NUMBER {
+				// Insert new station
+				nextStation := nw.trainLine[lineCode][prevStationNumber].NextStation
+				nw.trainLine[lineCode][prevStationNumber].NextStation = station
+				station.PrevStation = nw.trainLine[lineCode][prevStationNumber]
+				station.NextStation = nextStation
+				if nextStation != nil {
+					nextStation.PrevStation = station
+				}
+			}
+			if station.PrevStation == nil && nextStationNumber != INVALID_NEXT_STATION_NUMBER {
+				// new station is the 1st node
+				nw.trainLine[lineCode][nextStationNumber].PrevStation = station
+				station.NextStation = nw.trainLine[lineCode][nextStationNumber]
+			}
+			nw.trainLine[lineCode][stNumber] = station
+		} else {
+			nw.trainLine[lineCode] = map[int64]*common.Station{
+				stNumber: station,
+			}
+		}
+	}
+	if err := validateNetwork(nw); err != nil {
+		return nil, err
+	}
+	stationNames := make([]string, 0, len(nw.stationNameCodeMap))
+	for stationName := range nw.stationNameCodeMap {
+		stationNames = append(stationNames, stationName)
+	}
+	nw.stationIndex = newStationIndex(stationNames)
+	return nw, nil
+}
+
+// validates that the stations of the network are linked correctly
+// With the track links the trains should be able to run both to and from every station on the track links
+func validateNetwork(nw *Network) error {
+	if len(nw.stationCodeNameMap) == 0 {
+		return fmt.Errorf("no stations found in the station map")
+	}
+	if nw.hasTrackLinks() {
+		return validateTrackLinks(nw)
+	}
+	for lineCode, stations := range nw.trainLine {
+		// every station on the line should be reachable from the first station of the line
+		stationCount := 0
+		for station := nw.firstStation(lineCode); station != nil; station = station.NextStation {
+			stationCount++
+		}
+		if stationCount != len(stations) {
+			return fmt.Errorf("%s train line has stations that are not linked", lineCode)
+		}
+	}
+	return nil
+}
+
+// validates that none of the stations on the track links is a dead end that the trains can't run from or can't be reached
+func validateTrackLinks(nw *Network) error {
+	stationCodes := make([]string, 0, len(nw.stationCodeNameMap))
+	for stationCode := range nw.stationCodeNameMap {
+		stationCodes = append(stationCodes, stationCode)
+	}
+	sort.Strings(stationCodes)
+	for _, stationCode := range stationCodes {
+		if !nw.isOnTrackLinks(nw.station(stationCode)) {
+			continue // The stations that aren't on the track links are never travelled to e.g. CG0 when the trains run from EW4 to CG1
+		}
+		if len(nw.trackNextCodes[stationCode]) == 0 {
+			return fmt.Errorf("trains can't run from station %s on the track links", stationCode)
+		}
+		if len(nw.trackPrevCodes[stationCode]) == 0 {
+			return fmt.Errorf("trains can't run to station %s on the track links", stationCode)
+		}
+	}
+	return nil
+}
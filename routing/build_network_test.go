@@ -0,0 +1,61 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+)
+
+func TestNewNetwork(t *testing.T) {
+	t.Run("builds the network from the station map", func(t *testing.T) {
+		stationMap := "Station Code,Station Name,Opening Date\n" +
+			"AA1,Alpha,1 January 2000\n" +
+			"AA3,Gamma,1 January 2000\n" +
+			"AA2,Beta,1 January 2000\n" +
+			"BB1,Beta,December 2010\n" +
+			"BB2,Delta,2012\n"
+		nw, err := NewNetwork(strings.NewReader(stationMap), TrainLineTimeExceptionRules)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"AA2", "BB1"}, nw.stationNameCodeMap["Beta"])
+		assert.Equal(t, "Delta", nw.stationCodeNameMap["BB2"])
+
+		beta := nw.trainLine["AA"][2]
+		assert.Equal(t, "AA1", beta.PrevStation.Code)
+		assert.Equal(t, "AA3", beta.NextStation.Code)
+		assert.Equal(t, []*common.Station{nw.trainLine["BB"][1]}, beta.LinkedStations)
+		// The partial opening dates are resolved to the end of the period
+		assert.Equal(t, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), nw.trainLine["AA"][1].OpenedOn)
+		assert.Equal(t, time.Date(2010, 12, 31, 0, 0, 0, 0, time.UTC), nw.trainLine["BB"][1].OpenedOn)
+		assert.Equal(t, time.Date(2012, 12, 31, 0, 0, 0, 0, time.UTC), nw.trainLine["BB"][2].OpenedOn)
+
+		journeys, err := NewPlanner(nw).Plan(context.Background(), "Alpha", "Delta", WithNetworkDate(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(journeys))
+		assert.Equal(t, []string{"AA1", "AA2", "BB1", "BB2"}, journeys[0].StationCodes())
+
+		// Delta hasn't opened before the end of 2012
+		_, err = NewPlanner(nw).Plan(context.Background(), "Alpha", "Delta", WithNetworkDate(time.Date(2012, 12, 30, 0, 0, 0, 0, time.UTC)))
+		assert.Equal(t, &InvalidRequestError{Message: "destination station is not open on 2012-12-30"}, err)
+		journeys, err = NewPlanner(nw).Plan(context.Background(), "Alpha", "Delta", WithNetworkDate(time.Date(2012, 12, 31, 0, 0, 0, 0, time.UTC)))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(journeys))
+	})
+
+	t.Run("returns an error for an invalid station map", func(t *testing.T) {
+		stationMap := "Station Code,Station Name,Opening Date\n" +
+			"AA1,Alpha,someday\n"
+		_, err := NewNetwork(strings.NewReader(stationMap), TrainLineTimeExceptionRules)
+		assert.Equal(t, fmt.Errorf("error in parsing the opening date of station AA1: invalid opening date someday"), err)
+
+		_, err = NewNetwork(strings.NewReader("Station Code,Station Name,Opening Date\nAA1,Alpha\n"), TrainLineTimeExceptionRules)
+		assert.Equal(t, fmt.Errorf("error in reading the file row: record on line 2: wrong number of fields"), err)
+
+		_, err = NewNetwork(strings.NewReader("Station Code,Station Name,Opening Date\n"), TrainLineTimeExceptionRules)
+		assert.Equal(t, fmt.Errorf("no stations found in the station map"), err)
+	})
+}
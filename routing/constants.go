@@ -0,0 +1,109 @@
+package routing
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	INVALID_PREV_STATION_NUMBER = int64(-1)            // This is used in finding the closest station while inserting a new station
+	INVALID_NEXT_STATION_NUMBER = int64(math.MaxInt64) // This is used in finding the closest station while inserting a new station
+	DEFAULT_KEY                 = "default"            // For the TrainLineTimeExceptionRules map, the rules of this key apply to the train lines without rules
+	DATE_FORMAT                 = "2006-01-02"         // This is the format in which the dates are returned in the errors
+	DEFAULT_MAX_ROUTES          = 3                    // The number of journeys planned if the max routes isn't provided
+	MAX_ROUTES                  = 10                   // The maximum number of journeys that can be planned at once
+	DEFAULT_NETWORK_TIMEZONE    = "Asia/Singapore"     // The timezone in which the time rules are evaluated if it isn't configured
+	HOLIDAY_DAY_TYPE            = "Holiday"            // The day type of the rules that apply on the dates in the holiday calendar
+	WEEKEND_DAY_TYPE            = "Weekend"            // The day type of the rules that apply on Saturdays and Sundays
+	HOLIDAY_WEEKDAY             = time.Sunday          // The day of the week as which a holiday is considered for the DaysOfWeek rules
+	MINUTES_PER_DAY             = 24 * 60
+)
+
+// The values that a journey can be optimized for
+const (
+	OPTIMIZE_TIME      = "time"      // The least estimated time, needs a start or arrival time
+	OPTIMIZE_STATIONS  = "stations"  // The least number of stations travelled
+	OPTIMIZE_TRANSFERS = "transfers" // The least number of line changes
+)
+
+const MAX_SEGMENT_ESTIMATE_ITERATIONS = 5 // The number of times the backward search evaluates a segment again at its departure time before using the longest estimate
+
+// TrainLineTimeExceptionRules would have the list of rules that are configurable to assist in determining the best route based on the period of day and time taken
+/*
+The structure is
+{
+	<TrainLineCode>: {
+		Default: { // The train line meta that applies when none of the rules apply
+			NextStationTimeInMinutes: // This will give the estimated time to get to the next station
+			LineChangeTimeInMinutes: // This will give the estimated time to change the line on the same station
+		},
+		Rules: [
+			{
+				Start: // The start of the time range e.g. "10:00PM", it is included in the range
+				End: // The end of the time range e.g. "6:00AM", it is excluded from the range. The range wraps past midnight if the end isn't after the start
+				Priority: // The rule with the highest priority is used when more than one rule applies
+				NextStationTimeInMinutes: // This will give the estimated time to get to the next station
+				LineChangeTimeInMinutes: // This will give the estimated time to change the line on the same station
+				DaysOfWeek: // This is an array of the days of the week on which the time range starts
+				DayTypes: // This is an array of "Holiday", "Weekend" or dates like "2022-02-01" on which the time range starts
+				IsNotOperational: // Boolean to denote whether the line is operational in the time range. Since golang's default value for boolean is false
+								  // The value is only specified if line is not operational
+			}
+		]
+	}
+}
+*/
+var TrainLineTimeExceptionRules = TimeExceptionRule{
+	"NS": {
+		Rules: []*TimeRule{
+			{Start: "6:00AM", End: "9:00AM", TrainLineMeta: TrainLineMeta{NextStationTimeInMinutes: 12, LineChangeTimeInMinutes: 15, DaysOfWeek: workingDays}},
+			{Start: "6:00PM", End: "9:00PM", TrainLineMeta: TrainLineMeta{NextStationTimeInMinutes: 12, LineChangeTimeInMinutes: 15, DaysOfWeek: workingDays}},
+		},
+	},
+	"NE": {
+		Rules: []*TimeRule{
+			{Start: "6:00AM", End: "9:00AM", TrainLineMeta: TrainLineMeta{NextStationTimeInMinutes: 12, LineChangeTimeInMinutes: 15, DaysOfWeek: workingDays}},
+			{Start: "6:00PM", End: "9:00PM", TrainLineMeta: TrainLineMeta{NextStationTimeInMinutes: 12, LineChangeTimeInMinutes: 15, DaysOfWeek: workingDays}},
+		},
+	},
+	"DT": {
+		Default: &TrainLineMeta{NextStationTimeInMinutes: 8, LineChangeTimeInMinutes: 10},
+		Rules: []*TimeRule{
+			{Start: "10:00PM", End: "6:00AM", TrainLineMeta: TrainLineMeta{IsNotOperational: true, LineChangeTimeInMinutes: 10, DaysOfWeek: nightClosureDays}},
+			{Start: "6:00AM", End: "9:00AM", TrainLineMeta: TrainLineMeta{NextStationTimeInMinutes: 10, LineChangeTimeInMinutes: 15, DaysOfWeek: workingDays}},
+			{Start: "6:00PM", End: "9:00PM", TrainLineMeta: TrainLineMeta{NextStationTimeInMinutes: 10, LineChangeTimeInMinutes: 15, DaysOfWeek: workingDays}},
+		},
+	},
+	"CG": {
+		Rules: []*TimeRule{
+			{Start: "10:00PM", End: "6:00AM", TrainLineMeta: TrainLineMeta{IsNotOperational: true, LineChangeTimeInMinutes: 10, DaysOfWeek: nightClosureDays}},
+		},
+	},
+	"CE": {
+		Rules: []*TimeRule{
+			{Start: "10:00PM", End: "6:00AM", TrainLineMeta: TrainLineMeta{IsNotOperational: true, LineChangeTimeInMinutes: 10, DaysOfWeek: nightClosureDays}},
+		},
+	},
+	"TE": {
+		Default: &TrainLineMeta{NextStationTimeInMinutes: 8, LineChangeTimeInMinutes: 10},
+		Rules: []*TimeRule{
+			{Start: "10:00PM", End: "6:00AM", TrainLineMeta: TrainLineMeta{NextStationTimeInMinutes: 8, LineChangeTimeInMinutes: 10, DaysOfWeek: nightClosureDays}},
+			{Start: "6:00AM", End: "9:00AM", TrainLineMeta: TrainLineMeta{NextStationTimeInMinutes: 10, LineChangeTimeInMinutes: 15, DaysOfWeek: workingDays}},
+			{Start: "6:00PM", End: "9:00PM", TrainLineMeta: TrainLineMeta{NextStationTimeInMinutes: 10, LineChangeTimeInMinutes: 15, DaysOfWeek: workingDays}},
+		},
+	},
+	"default": {
+		Default: &TrainLineMeta{NextStationTimeInMinutes: 10, LineChangeTimeInMinutes: 10},
+		Rules: []*TimeRule{
+			{Start: "6:00AM", End: "9:00AM", TrainLineMeta: TrainLineMeta{NextStationTimeInMinutes: 10, LineChangeTimeInMinutes: 15, DaysOfWeek: workingDays}},
+			{Start: "6:00PM", End: "9:00PM", TrainLineMeta: TrainLineMeta{NextStationTimeInMinutes: 10, LineChangeTimeInMinutes: 15, DaysOfWeek: workingDays}},
+			{Start: "10:00PM", End: "6:00AM", TrainLineMeta: TrainLineMeta{NextStationTimeInMinutes: 10, LineChangeTimeInMinutes: 10, DaysOfWeek: nightClosureDays}},
+		},
+	},
+}
+
+// workingDays are the days of the week with the peak hours
+var workingDays = []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"}
+
+// nightClosureDays are the days of the week on which the night time rules start, they end on the morning of the next day
+var nightClosureDays = []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
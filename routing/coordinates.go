@@ -0,0 +1,76 @@
+package routing
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+)
+
+// StationCoordinates has the locations of the stations. Key is station code and value is the location of the platform
+type StationCoordinates map[string]*common.Coordinates
+
+// LoadStationCoordinates loads the station coordinates from the csv file at the path
+func LoadStationCoordinates(coordinatesPath string) (StationCoordinates, error) {
+	coordinatesFile, err := os.Open(coordinatesPath)
+	if err != nil {
+		return nil, err
+	}
+	defer coordinatesFile.Close()
+	return NewStationCoordinates(coordinatesFile)
+}
+
+// NewStationCoordinates reads the station coordinates from the csv reader
+// The csv has a header row followed by rows of the station code, latitude and longitude e.g.
+/*
+Station Code,Latitude,Longitude
+EW1,1.3733,103.9493
+*/
+func NewStationCoordinates(r io.Reader) (StationCoordinates, error) {
+	csvReader := csv.NewReader(r)
+	csvReader.FieldsPerRecord = 3
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid station coordinates file: %v", err)
+	}
+	coordinates := StationCoordinates{}
+	for idx, record := range records {
+		if idx == 0 {
+			continue // skip the header row
+		}
+		stationCode := strings.ToUpper(strings.TrimSpace(record[0]))
+		latitude, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil || latitude < -90 || latitude > 90 {
+			return nil, fmt.Errorf("invalid latitude %s of station %s", record[1], stationCode)
+		}
+		longitude, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if err != nil || longitude < -180 || longitude > 180 {
+			return nil, fmt.Errorf("invalid longitude %s of station %s", record[2], stationCode)
+		}
+		coordinates[stationCode] = &common.Coordinates{Latitude: latitude, Longitude: longitude}
+	}
+	return coordinates, nil
+}
+
+// WithStationCoordinates returns a copy of the network with the locations of the stations
+// The stations without coordinates are allowed, but every station code should be in the network
+func (nw *Network) WithStationCoordinates(coordinates StationCoordinates) (*Network, error) {
+	for stationCode := range coordinates {
+		if nw.station(stationCode) == nil {
+			return nil, fmt.Errorf("invalid station code %s in station coordinates", stationCode)
+		}
+	}
+	coordinatesNetwork := *nw
+	coordinatesNetwork.coordinates = coordinates
+	coordinatesNetwork.coordinateIndex = newSpatialIndex(coordinates)
+	return &coordinatesNetwork, nil
+}
+
+// stationCoordinates returns the location of the station code, nil if it doesn't have coordinates
+func (nw *Network) stationCoordinates(stationCode string) *common.Coordinates {
+	return nw.coordinates[stationCode]
+}
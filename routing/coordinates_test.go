@@ -0,0 +1,56 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+)
+
+func TestStationCoordinates(t *testing.T) {
+	coordinates, err := NewStationCoordinates(strings.NewReader("Station Code,Latitude,Longitude\nEW14,1.2840,103.8515\nns25,1.2931,103.8520\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, StationCoordinates{"EW14": {Latitude: 1.284, Longitude: 103.8515}, "NS25": {Latitude: 1.2931, Longitude: 103.852}}, coordinates)
+	nw, err := newTestNetwork(t).WithStationCoordinates(coordinates)
+	assert.Nil(t, err)
+
+	t.Run("exposes the coordinates on the stations", func(t *testing.T) {
+		assert.Equal(t, &common.Coordinates{Latitude: 1.284, Longitude: 103.8515}, nw.stationInfo("Raffles Place").Coordinates)
+		assert.Nil(t, nw.stationInfo("Bugis").Coordinates)
+		lineInfo, ok := nw.Line("NS")
+		assert.True(t, ok)
+		for _, lineStation := range lineInfo.Stations {
+			assert.Equal(t, coordinates[lineStation.Code], lineStation.Coordinates)
+		}
+	})
+
+	t.Run("returns the coordinates of the journey stations", func(t *testing.T) {
+		journeys, err := NewPlanner(nw).Plan(context.Background(), "EW14", "EW12")
+		assert.Nil(t, err)
+		assert.Equal(t, []*common.Coordinates{coordinates["EW14"], nil, nil}, journeys[0].Coordinates)
+		journeys, err = NewPlanner(newTestNetwork(t)).Plan(context.Background(), "EW14", "EW12")
+		assert.Nil(t, err)
+		assert.Nil(t, journeys[0].Coordinates)
+	})
+
+	t.Run("returns an error for invalid coordinates", func(t *testing.T) {
+		testCases := []struct {
+			coordinates string
+			err         error
+		}{
+			{coordinates: "Station Code,Latitude,Longitude\nEW14,north,103.8515\n", err: fmt.Errorf("invalid latitude north of station EW14")},
+			{coordinates: "Station Code,Latitude,Longitude\nEW14,1.2840,200\n", err: fmt.Errorf("invalid longitude 200 of station EW14")},
+			{coordinates: "Station Code,Latitude,Longitude\nXX1,1.2840,103.8515\n", err: fmt.Errorf("invalid station code XX1 in station coordinates")},
+		}
+		for _, testCase := range testCases {
+			coordinates, err := NewStationCoordinates(strings.NewReader(testCase.coordinates))
+			if err == nil {
+				_, err = newTestNetwork(t).WithStationCoordinates(coordinates)
+			}
+			assert.Equal(t, testCase.err, err)
+		}
+	})
+}
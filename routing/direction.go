@@ -0,0 +1,57 @@
+package routing
+
+import (
+	"time"
+
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+)
+
+// terminus returns the last station open on the network date on the train line in the direction of travel from the station to the next station
+// The direction is found by following the NextStation chain of the line instead of comparing the station numbers, so the branch stubs
+// that start at code 0 like CG0 Tanah Merah and CE0 Promenade have the junction station as the terminus when travelling towards it
+// With the track links the trains are followed along the track links instead
+func (nw *Network) terminus(station, nextStation *common.Station, networkDate time.Time) *common.Station {
+	if nw.hasTrackLinks() {
+		return nw.trackTerminus(station, nextStation, networkDate)
+	}
+	towardsNextStation := false
+	for lineStation := station.NextStation; lineStation != nil; lineStation = lineStation.NextStation {
+		if lineStation == nextStation {
+			towardsNextStation = true
+			break
+		}
+	}
+	terminusStation := nextStation
+	for lineStation := nextStation; lineStation != nil; {
+		if isStationOpen(lineStation, networkDate) {
+			terminusStation = lineStation
+		}
+		if towardsNextStation {
+			lineStation = lineStation.NextStation
+		} else {
+			lineStation = lineStation.PrevStation
+		}
+	}
+	return terminusStation
+}
+
+// trackTerminus returns the last station open on the network date that the trains reach along the track links after running from
+// the station to the next station. The trains stop at a junction where the way on is ambiguous, and a loop ends before it
+// comes back to a station already passed
+func (nw *Network) trackTerminus(station, nextStation *common.Station, networkDate time.Time) *common.Station {
+	previousStation := station
+	// The trains may pass through stations between the station and the next station
+	if stations := nw.trackPath(station, nextStation); len(stations) > 1 {
+		previousStation = stations[len(stations)-2]
+	}
+	visitedStations := map[string]bool{station.Code: true}
+	terminusStation := nextStation
+	for lineStation := nextStation; lineStation != nil && !visitedStations[lineStation.Code]; {
+		visitedStations[lineStation.Code] = true
+		if isStationOpen(lineStation, networkDate) {
+			terminusStation = lineStation
+		}
+		previousStation, lineStation = lineStation, nw.trackContinuation(previousStation, lineStation)
+	}
+	return terminusStation
+}
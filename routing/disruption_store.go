@@ -0,0 +1,181 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// DisruptionStore holds the disruptions and persists them to a json file so that they are kept across restarts
+// The disruptions are only kept in memory if there's no file
+type DisruptionStore struct {
+	path        string
+	mutex       sync.RWMutex
+	disruptions map[string]*Disruption // Keyed by the disruption ID
+	lastID      int64
+}
+
+// NewDisruptionStore returns a store with the disruptions in the json file at the path, the file is created on the first change if it doesn't exist
+func NewDisruptionStore(path string) (*DisruptionStore, error) {
+	store := &DisruptionStore{path: path, disruptions: map[string]*Disruption{}}
+	if path == "" {
+		return store, nil
+	}
+	disruptionsFile, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var disruptions []*Disruption
+	if err := json.Unmarshal(disruptionsFile, &disruptions); err != nil {
+		return nil, fmt.Errorf("invalid disruptions file: %v", err)
+	}
+	for _, disruption := range disruptions {
+		store.disruptions[disruption.ID] = disruption
+		if id, err := strconv.ParseInt(disruption.ID, 10, 64); err == nil && id > store.lastID {
+			store.lastID = id
+		}
+	}
+	return store, nil
+}
+
+// Validate checks that every disruption in the store is valid on the network, so that the disruptions persisted in the file
+// which no longer refer to the stations and train lines on the network aren't planned around
+func (s *DisruptionStore) Validate(nw *Network) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for _, disruption := range s.sortedDisruptions() {
+		if err := nw.ValidateDisruption(disruption); err != nil {
+			return fmt.Errorf("invalid disruption %s: %v", disruption.ID, err)
+		}
+	}
+	return nil
+}
+
+// NetworkLoader returns a loader which fails if the disruptions in the store aren't valid on the network built by the loader,
+// so that a reload which drops or renames the stations of a disruption keeps the network in use until the disruption is changed
+func (s *DisruptionStore) NetworkLoader(loader func() (*Network, error)) func() (*Network, error) {
+	return func() (*Network, error) {
+		nw, err := loader()
+		if err != nil {
+			return nil, err
+		}
+		if err := s.Validate(nw); err != nil {
+			return nil, err
+		}
+		return nw, nil
+	}
+}
+
+// LoadDisruptionStoreFromEnv returns the store of the disruptions persisted at DISRUPTIONS_PATH, they are only kept in memory if it isn't defined
+func LoadDisruptionStoreFromEnv() (*DisruptionStore, error) {
+	return NewDisruptionStore(os.Getenv("DISRUPTIONS_PATH"))
+}
+
+// Disruptions returns all the disruptions ordered by their start time
+func (s *DisruptionStore) Disruptions() []*Disruption {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.sortedDisruptions()
+}
+
+// Disruption returns the disruption with the ID
+func (s *DisruptionStore) Disruption(id string) (*Disruption, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	disruption, ok := s.disruptions[id]
+	return disruption, ok
+}
+
+// Add assigns an ID to the disruption and stores it
+func (s *DisruptionStore) Add(disruption *Disruption) (*Disruption, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	added := *disruption
+	added.ID = strconv.FormatInt(s.lastID+1, 10)
+	s.disruptions[added.ID] = &added
+	if err := s.save(); err != nil {
+		delete(s.disruptions, added.ID)
+		return nil, err
+	}
+	s.lastID++
+	return &added, nil
+}
+
+// Update replaces the disruption with the ID, it returns false if there's no such disruption
+func (s *DisruptionStore) Update(id string, disruption *Disruption) (*Disruption, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	existing, ok := s.disruptions[id]
+	if !ok {
+		return nil, false, nil
+	}
+	updated := *disruption
+	updated.ID = id
+	s.disruptions[id] = &updated
+	if err := s.save(); err != nil {
+		s.disruptions[id] = existing
+		return nil, true, err
+	}
+	return &updated, true, nil
+}
+
+// Remove removes the disruption with the ID, it returns false if there's no such disruption
+func (s *DisruptionStore) Remove(id string) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	existing, ok := s.disruptions[id]
+	if !ok {
+		return false, nil
+	}
+	delete(s.disruptions, id)
+	if err := s.save(); err != nil {
+		s.disruptions[id] = existing
+		return true, err
+	}
+	return true, nil
+}
+
+func (s *DisruptionStore) sortedDisruptions() []*Disruption {
+	disruptions := make([]*Disruption, 0, len(s.disruptions))
+	for _, disruption := range s.disruptions {
+		disruptions = append(disruptions, disruption)
+	}
+	sort.Slice(disruptions, func(i, j int) bool {
+		if !disruptions[i].Start.Equal(disruptions[j].Start) {
+			return disruptions[i].Start.Before(disruptions[j].Start)
+		}
+		return disruptions[i].ID < disruptions[j].ID
+	})
+	return disruptions
+}
+
+// save writes the disruptions to a temporary file which then replaces the file so that a partially written file is never read
+func (s *DisruptionStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	disruptionsFile, err := json.MarshalIndent(s.sortedDisruptions(), "", "  ")
+	if err != nil {
+		return err
+	}
+	tempFile, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+	if _, err := tempFile.Write(disruptionsFile); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tempFile.Name(), s.path)
+}
@@ -0,0 +1,279 @@
+package routing
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+	"gitlab.myteksi.net/goscripts/zendesk/utils"
+)
+
+const (
+	STATION_DISRUPTION = "station" // Closes a station, the trains pass through it without stopping
+	SEGMENT_DISRUPTION = "segment" // Closes a train line between two of its stations
+	LINE_DISRUPTION    = "line"    // Closes a whole train line
+)
+
+// Disruption closes a station, a segment of a train line or a whole train line from the start time until the end time
+type Disruption struct {
+	ID          string
+	Type        string    // STATION_DISRUPTION, SEGMENT_DISRUPTION or LINE_DISRUPTION
+	Station     string    // The closed station of a station disruption. A station name closes all of its platforms, a station code only the platform of that line
+	From        string    // The station code from which a segment disruption closes the line
+	To          string    // The station code up to which a segment disruption closes the line, it is on the same line as From
+	Line        string    // The closed train line code of a line disruption
+	Start       time.Time // The disruption is active from the start time
+	End         time.Time // The disruption is active until the end time, it is active until it is removed if the end time is zero
+	Description string
+}
+
+// DisruptionProvider provides the disruptions to be considered for a plan
+type DisruptionProvider interface {
+	Disruptions() []*Disruption
+}
+
+// ValidateDisruption checks whether the disruption is complete and refers to the stations and train lines on the network
+// Every invalid disruption returns the InvalidRequestError
+func (nw *Network) ValidateDisruption(d *Disruption) error {
+	if d.Start.IsZero() {
+		return &InvalidRequestError{Message: "missing start time of disruption"}
+	}
+	if !d.End.IsZero() && !d.End.After(d.Start) {
+		return &InvalidRequestError{Message: "end time of disruption should be after the start time"}
+	}
+	switch d.Type {
+	case STATION_DISRUPTION:
+		if _, ok := nw.resolveStationCodes(d.Station); !ok {
+			return &InvalidRequestError{Message: fmt.Sprintf("invalid station %s of disruption", d.Station)}
+		}
+	case SEGMENT_DISRUPTION:
+		if d.From == "" || d.To == "" {
+			return &InvalidRequestError{Message: "missing from or to station of disruption"}
+		}
+		for _, stationCode := range []string{d.From, d.To} {
+			if _, ok := nw.stationCodeNameMap[stationCode]; !ok {
+				return &InvalidRequestError{Message: fmt.Sprintf("invalid station %s of disruption", stationCode)}
+			}
+		}
+		fromLineName, _, fromErr := utils.GetStationMetadataFromCode(d.From)
+		toLineName, _, toErr := utils.GetStationMetadataFromCode(d.To)
+		if fromErr != nil || toErr != nil || d.From == d.To || fromLineName != toLineName {
+			return &InvalidRequestError{Message: fmt.Sprintf("stations %s and %s of disruption should be different stations on the same line", d.From, d.To)}
+		}
+		if nw.hasTrackLinks() && nw.trackSegment(nw.station(d.From), nw.station(d.To)) == nil {
+			return &InvalidRequestError{Message: fmt.Sprintf("stations %s and %s of disruption aren't linked by the track links", d.From, d.To)}
+		}
+	case LINE_DISRUPTION:
+		if !nw.HasLine(d.Line) {
+			return &InvalidRequestError{Message: fmt.Sprintf("invalid line %s of disruption", d.Line)}
+		}
+	default:
+		return &InvalidRequestError{Message: fmt.Sprintf("invalid disruption type %s", d.Type)}
+	}
+	return nil
+}
+
+// Info returns the details of the disruption with the times in RFC3339 format
+func (d *Disruption) Info() *common.DisruptionInfo {
+	disruptionInfo := &common.DisruptionInfo{
+		ID:          d.ID,
+		Type:        d.Type,
+		Station:     d.Station,
+		From:        d.From,
+		To:          d.To,
+		Line:        d.Line,
+		Start:       d.Start.Format(time.RFC3339),
+		Description: d.Description,
+	}
+	if !d.End.IsZero() {
+		disruptionInfo.End = d.End.Format(time.RFC3339)
+	}
+	return disruptionInfo
+}
+
+// isActiveDuring checks whether the disruption is active at any time from the start time until the end time
+func (d *Disruption) isActiveDuring(startTime, endTime time.Time) bool {
+	return d.Start.Before(endTime) && (d.End.IsZero() || d.End.After(startTime))
+}
+
+// isStationClosed checks whether the station can't be stopped at due to the disruption
+func (d *Disruption) isStationClosed(station *common.Station) bool {
+	switch d.Type {
+	case STATION_DISRUPTION:
+		return strings.EqualFold(d.Station, station.Code) || d.Station == station.Name
+	case LINE_DISRUPTION:
+		return d.Line == station.Code[:2]
+	}
+	return false
+}
+
+// isSegmentClosed checks whether travelling between the stations on the same line isn't possible due to the disruption
+// The stations can be more than one station apart when the trains pass through the stations between them
+// With the track links the segments are closed if the runs between their stations share any track link
+func (d *Disruption) isSegmentClosed(nw *Network, station, nextStation *common.Station) bool {
+	if d.Type == SEGMENT_DISRUPTION && nw.hasTrackLinks() {
+		closedLinks := trackLinkKeys(nw.trackSegment(nw.station(d.From), nw.station(d.To)))
+		for linkKey := range trackLinkKeys(nw.trackPath(station, nextStation)) {
+			if closedLinks[linkKey] {
+				return true
+			}
+		}
+		return false
+	}
+	lineName, stationNumber, err := utils.GetStationMetadataFromCode(station.Code)
+	if err != nil {
+		return false
+	}
+	_, nextStationNumber, err := utils.GetStationMetadataFromCode(nextStation.Code)
+	if err != nil {
+		return false
+	}
+	switch d.Type {
+	case LINE_DISRUPTION:
+		return d.Line == nw.rideLine(station.Code, nextStation.Code)
+	case SEGMENT_DISRUPTION:
+		fromLineName, fromNumber, err := utils.GetStationMetadataFromCode(d.From)
+		if err != nil || fromLineName != lineName {
+			return false
+		}
+		_, toNumber, err := utils.GetStationMetadataFromCode(d.To)
+		if err != nil {
+			return false
+		}
+		return isOverlappingSegment(stationNumber, nextStationNumber, fromNumber, toNumber)
+	}
+	return false
+}
+
+// isOverlappingSegment checks whether the segments between the station numbers share any part of the line
+func isOverlappingSegment(start, end, otherStart, otherEnd int64) bool {
+	if start > end {
+		start, end = end, start
+	}
+	if otherStart > otherEnd {
+		otherStart, otherEnd = otherEnd, otherStart
+	}
+	return start < otherEnd && otherStart < end
+}
+
+// lines returns the train line codes that the disruption is on
+func (d *Disruption) lines(nw *Network) []string {
+	switch d.Type {
+	case STATION_DISRUPTION:
+		stationCodes, _ := nw.resolveStationCodes(d.Station)
+		var lines []string
+		for _, stationCode := range stationCodes {
+			if lineName, _, err := utils.GetStationMetadataFromCode(stationCode); err == nil {
+				lines = append(lines, lineName)
+			}
+		}
+		return lines
+	case SEGMENT_DISRUPTION:
+		lineName, _, err := utils.GetStationMetadataFromCode(d.From)
+		if err != nil {
+			return nil
+		}
+		return []string{lineName}
+	}
+	return []string{d.Line}
+}
+
+// stationNames returns the names of the stations closed by a station disruption or at the ends of and within a closed segment
+func (d *Disruption) stationNames(nw *Network) []string {
+	switch d.Type {
+	case STATION_DISRUPTION:
+		stationCodes, _ := nw.resolveStationCodes(d.Station)
+		var names []string
+		for _, stationCode := range stationCodes {
+			names = append(names, nw.stationCodeNameMap[stationCode])
+		}
+		return names
+	case SEGMENT_DISRUPTION:
+		if nw.hasTrackLinks() {
+			var names []string
+			for _, station := range nw.trackSegment(nw.station(d.From), nw.station(d.To)) {
+				names = append(names, station.Name)
+			}
+			return names
+		}
+		lineName, fromNumber, err := utils.GetStationMetadataFromCode(d.From)
+		if err != nil {
+			return nil
+		}
+		_, toNumber, err := utils.GetStationMetadataFromCode(d.To)
+		if err != nil {
+			return nil
+		}
+		if fromNumber > toNumber {
+			fromNumber, toNumber = toNumber, fromNumber
+		}
+		var names []string
+		for stationNumber := fromNumber; stationNumber <= toNumber; stationNumber++ {
+			if station, ok := nw.trainLine[lineName][stationNumber]; ok {
+				names = append(names, station.Name)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// activeDisruptions returns the disruptions of the plan that are active at any time from the start time until the end time
+func activeDisruptions(disruptions []*Disruption, startTime, endTime time.Time) []*Disruption {
+	var active []*Disruption
+	for _, disruption := range disruptions {
+		if disruption.isActiveDuring(startTime, endTime) {
+			active = append(active, disruption)
+		}
+	}
+	return active
+}
+
+// isStationClosed checks whether any of the disruptions closes the station
+func isStationClosed(disruptions []*Disruption, station *common.Station) bool {
+	for _, disruption := range disruptions {
+		if disruption.isStationClosed(station) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSegmentClosed checks whether any of the disruptions closes the line between the stations
+func isSegmentClosed(nw *Network, disruptions []*Disruption, station, nextStation *common.Station) bool {
+	for _, disruption := range disruptions {
+		if disruption.isSegmentClosed(nw, station, nextStation) {
+			return true
+		}
+	}
+	return false
+}
+
+// journeyDisruptions returns the disruptions on the train lines that the journey travels on or at the stations that it stops at
+// These are the disruptions that the journey may have been planned around
+func journeyDisruptions(nw *Network, disruptions []*Disruption, stations []*common.Station) []*Disruption {
+	journeyLines, journeyStationNames := map[string]bool{}, map[string]bool{}
+	for idx, station := range stations {
+		journeyStationNames[station.Name] = true
+		if idx+1 < len(stations) && !nw.isTransfer(station, stations[idx+1]) {
+			journeyLines[nw.rideLine(station.Code, stations[idx+1].Code)] = true
+		}
+	}
+	var affectingDisruptions []*Disruption
+	for _, disruption := range disruptions {
+		if containsAny(journeyLines, disruption.lines(nw)) || containsAny(journeyStationNames, disruption.stationNames(nw)) {
+			affectingDisruptions = append(affectingDisruptions, disruption)
+		}
+	}
+	return affectingDisruptions
+}
+
+func containsAny(set map[string]bool, values []string) bool {
+	for _, value := range values {
+		if set[value] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testDisruptions []*Disruption
+
+func (d testDisruptions) Disruptions() []*Disruption {
+	return d
+}
+
+func TestPlanAroundDisruptions(t *testing.T) {
+	nw, err := NewEmbeddedNetwork(TrainLineTimeExceptionRules)
+	assert.Nil(t, err)
+	trackWorks := &Disruption{
+		ID:    "1",
+		Type:  SEGMENT_DISRUPTION,
+		From:  "EW21",
+		To:    "EW24",
+		Start: time.Date(2022, 1, 29, 5, 0, 0, 0, time.UTC),
+		End:   time.Date(2022, 1, 31, 5, 0, 0, 0, time.UTC),
+	}
+	planner := NewPlanner(nw, WithLocation(time.UTC), WithDisruptions(testDisruptions{trackWorks}))
+
+	t.Run("routes around the closed segment while it is active", func(t *testing.T) {
+		journeys, err := planner.Plan(context.Background(), "EW24", "EW21", WithStartTime(time.Date(2022, 1, 30, 8, 0, 0, 0, time.UTC)))
+		assert.Nil(t, err)
+		assert.NotEmpty(t, journeys)
+		for _, journey := range journeys {
+			assert.NotContains(t, journey.StationCodes(), "EW23")
+			assert.NotContains(t, journey.StationCodes(), "EW22")
+		}
+		assert.Equal(t, []*Disruption{trackWorks}, journeys[0].Disruptions)
+	})
+
+	t.Run("uses the segment once the disruption ends", func(t *testing.T) {
+		journeys, err := planner.Plan(context.Background(), "EW24", "EW21", WithStartTime(time.Date(2022, 1, 31, 8, 0, 0, 0, time.UTC)))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"EW24", "EW23", "EW22", "EW21"}, journeys[0].StationCodes())
+		assert.Empty(t, journeys[0].Disruptions)
+	})
+
+	t.Run("considers the disruptions active on the network date without a start time", func(t *testing.T) {
+		journeys, err := planner.Plan(context.Background(), "EW24", "EW21", WithNetworkDate(time.Date(2022, 1, 31, 0, 0, 0, 0, time.UTC)))
+		assert.Nil(t, err)
+		assert.NotContains(t, journeys[0].StationCodes(), "EW23")
+	})
+
+	t.Run("passes through the closed station", func(t *testing.T) {
+		closedStation := &Disruption{ID: "2", Type: STATION_DISRUPTION, Station: "Clementi", Start: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}
+		planner := NewPlanner(nw, WithLocation(time.UTC), WithDisruptions(testDisruptions{closedStation}))
+		journeys, err := planner.Plan(context.Background(), "EW24", "EW22", WithStartTime(time.Date(2022, 1, 31, 8, 0, 0, 0, time.UTC)))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"EW24", "EW22"}, journeys[0].StationCodes())
+		assert.Equal(t, []*Disruption{closedStation}, journeys[0].Disruptions)
+	})
+
+	t.Run("doesn't use the closed line", func(t *testing.T) {
+		closedLine := &Disruption{ID: "3", Type: LINE_DISRUPTION, Line: "CC", Start: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}
+		planner := NewPlanner(nw, WithLocation(time.UTC), WithDisruptions(testDisruptions{closedLine}))
+		journeys, err := planner.Plan(context.Background(), "Boon Lay", "Little India", WithMaxRoutes(MAX_ROUTES), WithStartTime(time.Date(2022, 1, 31, 8, 0, 0, 0, time.UTC)))
+		assert.Nil(t, err)
+		assert.NotEmpty(t, journeys)
+		for _, journey := range journeys {
+			for _, stationCode := range journey.StationCodes() {
+				assert.NotEqual(t, "CC", stationCode[:2])
+			}
+		}
+	})
+}
+
+func TestValidateDisruption(t *testing.T) {
+	nw, err := NewEmbeddedNetwork(TrainLineTimeExceptionRules)
+	assert.Nil(t, err)
+	start := time.Date(2022, 1, 29, 5, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		disruption *Disruption
+		err        error
+	}{
+		{disruption: &Disruption{Type: SEGMENT_DISRUPTION, From: "EW21", To: "EW24", Start: start}, err: nil},
+		{disruption: &Disruption{Type: STATION_DISRUPTION, Station: "Clementi", Start: start, End: start.Add(time.Hour)}, err: nil},
+		{disruption: &Disruption{Type: LINE_DISRUPTION, Line: "CC", Start: start}, err: nil},
+		{disruption: &Disruption{Type: LINE_DISRUPTION, Line: "CC"}, err: &InvalidRequestError{Message: "missing start time of disruption"}},
+		{disruption: &Disruption{Type: LINE_DISRUPTION, Line: "CC", Start: start, End: start}, err: &InvalidRequestError{Message: "end time of disruption should be after the start time"}},
+		{disruption: &Disruption{Type: LINE_DISRUPTION, Line: "XX", Start: start}, err: &InvalidRequestError{Message: "invalid line XX of disruption"}},
+		{disruption: &Disruption{Type: STATION_DISRUPTION, Station: "Atlantis", Start: start}, err: &InvalidRequestError{Message: "invalid station Atlantis of disruption"}},
+		{disruption: &Disruption{Type: SEGMENT_DISRUPTION, From: "EW21", To: "NS1", Start: start}, err: &InvalidRequestError{Message: "stations EW21 and NS1 of disruption should be different stations on the same line"}},
+		{disruption: &Disruption{Type: "track works", Start: start}, err: &InvalidRequestError{Message: "invalid disruption type track works"}},
+		{disruption: &Disruption{Type: SEGMENT_DISRUPTION, From: "A", To: "NS1", Start: start}, err: &InvalidRequestError{Message: "invalid station A of disruption"}},
+		{disruption: &Disruption{Type: SEGMENT_DISRUPTION, From: "NS1", To: "NS", Start: start}, err: &InvalidRequestError{Message: "invalid station NS of disruption"}},
+		{disruption: &Disruption{Type: SEGMENT_DISRUPTION, From: "", To: "NS1", Start: start}, err: &InvalidRequestError{Message: "missing from or to station of disruption"}},
+		{disruption: &Disruption{Type: SEGMENT_DISRUPTION, From: "NS1", Start: start}, err: &InvalidRequestError{Message: "missing from or to station of disruption"}},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.err, nw.ValidateDisruption(testCase.disruption))
+	}
+	// The codes too short to have a line and a number aren't on the network
+	assert.Nil(t, nw.station("A"))
+	assert.Nil(t, nw.station(""))
+}
+
+func TestDisruptionStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "disruptions.json")
+	store, err := NewDisruptionStore(path)
+	assert.Nil(t, err)
+	start := time.Date(2022, 1, 29, 5, 0, 0, 0, time.UTC)
+
+	trackWorks, err := store.Add(&Disruption{Type: SEGMENT_DISRUPTION, From: "EW21", To: "EW24", Start: start})
+	assert.Nil(t, err)
+	assert.Equal(t, "1", trackWorks.ID)
+	closedLine, err := store.Add(&Disruption{Type: LINE_DISRUPTION, Line: "CC", Start: start.Add(-time.Hour)})
+	assert.Nil(t, err)
+	assert.Equal(t, "2", closedLine.ID)
+
+	updated, ok, err := store.Update("1", &Disruption{Type: SEGMENT_DISRUPTION, From: "EW21", To: "EW23", Start: start})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "EW23", updated.To)
+	_, ok, err = store.Update("3", trackWorks)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	// The disruptions are kept across restarts
+	store, err = NewDisruptionStore(path)
+	assert.Nil(t, err)
+	disruptions := store.Disruptions()
+	assert.Equal(t, 2, len(disruptions))
+	assert.Equal(t, "2", disruptions[0].ID) // Ordered by the start time
+	assert.Equal(t, "EW23", disruptions[1].To)
+
+	ok, err = store.Remove("2")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	store, err = NewDisruptionStore(path)
+	assert.Nil(t, err)
+	_, ok = store.Disruption("2")
+	assert.False(t, ok)
+	added, err := store.Add(&Disruption{Type: LINE_DISRUPTION, Line: "CC", Start: start})
+	assert.Nil(t, err)
+	assert.Equal(t, "2", added.ID) // The IDs continue from the last disruption kept
+
+	nw, err := NewEmbeddedNetwork(TrainLineTimeExceptionRules)
+	assert.Nil(t, err)
+	assert.Nil(t, store.Validate(nw))
+}
+
+func TestDisruptionStoreValidate(t *testing.T) {
+	nw, err := NewEmbeddedNetwork(TrainLineTimeExceptionRules)
+	assert.Nil(t, err)
+	path := filepath.Join(t.TempDir(), "disruptions.json")
+	disruptionsFile := `[{"ID": "1", "Type": "segment", "From": "E", "To": "EW24", "Start": "2022-01-29T05:00:00Z"}]`
+	assert.Nil(t, os.WriteFile(path, []byte(disruptionsFile), 0644))
+	store, err := NewDisruptionStore(path)
+	assert.Nil(t, err)
+	assert.Equal(t, fmt.Errorf("invalid disruption 1: invalid station E of disruption"), store.Validate(nw))
+
+	// The planner doesn't fail on the invalid disruption if it's planned around anyway
+	disruption, _ := store.Disruption("1")
+	assert.Empty(t, disruption.lines(nw))
+	planner := NewPlanner(nw, WithLocation(time.UTC), WithDisruptions(store))
+	journeys, err := planner.Plan(context.Background(), "Clementi", "Jurong East", WithStartTime(time.Date(2022, 1, 31, 12, 0, 0, 0, time.UTC)))
+	assert.Nil(t, err)
+	assert.NotEmpty(t, journeys)
+}
@@ -0,0 +1,228 @@
+package routing
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+	"gitlab.myteksi.net/goscripts/zendesk/data"
+)
+
+// DEFAULT_SEGMENT_DISTANCE_IN_KM is the distance between adjacent stations used for the fares if it isn't in the segment distances
+const DEFAULT_SEGMENT_DISTANCE_IN_KM = 1.2
+
+// FareAmount has the fares of a fare class paying by card and by a single trip ticket
+type FareAmount struct {
+	CardInCents       int64
+	SingleTripInCents int64
+}
+
+// Fare has the fares of a journey for each fare class
+type Fare struct {
+	DistanceInKm float64 // The distance travelled on the trains that the fares are based on
+	Adult        FareAmount
+	Student      FareAmount
+	Senior       FareAmount
+}
+
+// FareBand has the fares of the journeys up to the max distance
+type FareBand struct {
+	MaxDistanceInKm float64 // This is +Inf for the last band that has no limit
+	Adult           FareAmount
+	Student         FareAmount
+	Senior          FareAmount
+}
+
+// FareTable has the fare bands in the ascending order of the max distance
+type FareTable struct {
+	bands []*FareBand
+}
+
+// SegmentDistances has the distances in km between the adjacent stations of a line keyed by the segment key of the station codes
+type SegmentDistances map[string]float64
+
+// LoadFareTable loads the fare table from the csv file at the path
+func LoadFareTable(fareTablePath string) (*FareTable, error) {
+	fareTableFile, err := os.Open(fareTablePath)
+	if err != nil {
+		return nil, err
+	}
+	defer fareTableFile.Close()
+	return NewFareTable(fareTableFile)
+}
+
+// NewEmbeddedFareTable reads the fare table compiled into the binary
+func NewEmbeddedFareTable() (*FareTable, error) {
+	return NewFareTable(bytes.NewReader(data.FareTable))
+}
+
+// NewFareTable reads the fare table from the csv reader
+// The csv has a header row followed by rows of the max distance in km and the fares in cents of adult, student and senior
+// fare classes paying by card and by single trip ticket. The max distance of the last band can be empty for no limit e.g.
+/*
+Max Distance (km),Adult Card,Adult Single Trip,Student Card,Student Single Trip,Senior Card,Senior Single Trip
+3.2,92,170,45,170,65,170
+,202,270,77,270,107,270
+*/
+func NewFareTable(r io.Reader) (*FareTable, error) {
+	csvReader := csv.NewReader(r)
+	csvReader.FieldsPerRecord = 7
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid fare table file: %v", err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("no fare bands found in the fare table")
+	}
+	fareTable := &FareTable{}
+	for _, record := range records[1:] { // skip the header row
+		band := &FareBand{MaxDistanceInKm: math.Inf(1)}
+		if maxDistance := strings.TrimSpace(record[0]); maxDistance != "" {
+			band.MaxDistanceInKm, err = strconv.ParseFloat(maxDistance, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid distance %s in fare table", maxDistance)
+			}
+		}
+		fares := make([]int64, 0, 6)
+		for _, value := range record[1:] {
+			fare, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil || fare < 0 {
+				return nil, fmt.Errorf("invalid fare %s in fare table", value)
+			}
+			fares = append(fares, fare)
+		}
+		band.Adult = FareAmount{CardInCents: fares[0], SingleTripInCents: fares[1]}
+		band.Student = FareAmount{CardInCents: fares[2], SingleTripInCents: fares[3]}
+		band.Senior = FareAmount{CardInCents: fares[4], SingleTripInCents: fares[5]}
+		if len(fareTable.bands) > 0 && fareTable.bands[len(fareTable.bands)-1].MaxDistanceInKm >= band.MaxDistanceInKm {
+			return nil, fmt.Errorf("fare table bands should be in ascending order of distance")
+		}
+		fareTable.bands = append(fareTable.bands, band)
+	}
+	return fareTable, nil
+}
+
+// fare returns the fare for the distance, the fares of the last band are used if the distance is beyond all the bands
+func (t *FareTable) fare(distanceInKm float64) *Fare {
+	band := t.bands[len(t.bands)-1]
+	for _, fareBand := range t.bands {
+		if distanceInKm <= fareBand.MaxDistanceInKm {
+			band = fareBand
+			break
+		}
+	}
+	return &Fare{DistanceInKm: distanceInKm, Adult: band.Adult, Student: band.Student, Senior: band.Senior}
+}
+
+// LoadSegmentDistances loads the segment distances from the csv file at the path
+func LoadSegmentDistances(distancesPath string) (SegmentDistances, error) {
+	distancesFile, err := os.Open(distancesPath)
+	if err != nil {
+		return nil, err
+	}
+	defer distancesFile.Close()
+	return NewSegmentDistances(distancesFile)
+}
+
+// NewSegmentDistances reads the segment distances from the csv reader
+// The csv has a header row followed by rows of the station codes of adjacent stations and the distance between them in km e.g.
+/*
+From Code,To Code,Distance (km)
+NS1,NS2,2.2
+*/
+func NewSegmentDistances(r io.Reader) (SegmentDistances, error) {
+	csvReader := csv.NewReader(r)
+	csvReader.FieldsPerRecord = 3
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid segment distances file: %v", err)
+	}
+	distances := SegmentDistances{}
+	for idx, record := range records {
+		if idx == 0 {
+			continue // skip the header row
+		}
+		startCode, endCode := strings.ToUpper(strings.TrimSpace(record[0])), strings.ToUpper(strings.TrimSpace(record[1]))
+		distance, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if err != nil || distance < 0 {
+			return nil, fmt.Errorf("invalid distance %s between %s and %s", record[2], startCode, endCode)
+		}
+		distances[segmentKey(startCode, endCode)] = distance
+	}
+	return distances, nil
+}
+
+// WithFares returns a copy of the network that calculates the fares of the journeys with the fare table and the segment distances
+// DEFAULT_SEGMENT_DISTANCE_IN_KM is used for the segments without a distance
+func (nw *Network) WithFares(fareTable *FareTable, distances SegmentDistances) *Network {
+	fareNetwork := *nw
+	fareNetwork.fareTable = fareTable
+	fareNetwork.segmentDistances = distances
+	return &fareNetwork
+}
+
+// journeyFare returns the fare of travelling through the stations, nil if the network doesn't have a fare table
+func (nw *Network) journeyFare(stations []*common.Station) *Fare {
+	if nw.fareTable == nil {
+		return nil
+	}
+	var distance float64
+	for idx := 0; idx+1 < len(stations); idx++ {
+		if nw.isTransfer(stations[idx], stations[idx+1]) {
+			continue // Changing lines or walking to another station doesn't add to the distance
+		}
+		distance += nw.rideDistance(stations[idx], stations[idx+1])
+	}
+	return nw.fareTable.fare(math.Round(distance*10) / 10)
+}
+
+// rideDistance returns the distance between the stations of a line including the stations passed through without stopping
+// With the track links the distance is along the track links that the trains run through
+func (nw *Network) rideDistance(startStation, endStation *common.Station) float64 {
+	if nw.hasTrackLinks() {
+		stations := nw.trackPath(startStation, endStation)
+		if stations == nil {
+			return nw.segmentDistance(startStation.Code, endStation.Code)
+		}
+		var distance float64
+		for idx := 0; idx+1 < len(stations); idx++ {
+			distance += nw.segmentDistance(stations[idx].Code, stations[idx+1].Code)
+		}
+		return distance
+	}
+	for _, towardsNextStation := range []bool{true, false} {
+		var distance float64
+		station := startStation
+		for station != nil && station.Code != endStation.Code {
+			nextStation := station.PrevStation
+			if towardsNextStation {
+				nextStation = station.NextStation
+			}
+			if nextStation != nil {
+				distance += nw.segmentDistance(station.Code, nextStation.Code)
+			}
+			station = nextStation
+		}
+		if station != nil {
+			return distance
+		}
+	}
+	return nw.segmentDistance(startStation.Code, endStation.Code)
+}
+
+// segmentDistance returns the distance between the adjacent stations in either direction
+func (nw *Network) segmentDistance(startStationCode, endStationCode string) float64 {
+	if distance, ok := nw.segmentDistances[segmentKey(startStationCode, endStationCode)]; ok {
+		return distance
+	}
+	if distance, ok := nw.segmentDistances[segmentKey(endStationCode, startStationCode)]; ok {
+		return distance
+	}
+	return DEFAULT_SEGMENT_DISTANCE_IN_KM
+}
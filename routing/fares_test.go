@@ -0,0 +1,93 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFareTable(t *testing.T) {
+	fareTable, err := NewEmbeddedFareTable()
+	assert.Nil(t, err)
+
+	testCases := []struct {
+		distance float64
+		fare     FareAmount
+	}{
+		{distance: 0, fare: FareAmount{CardInCents: 92, SingleTripInCents: 170}},
+		{distance: 3.2, fare: FareAmount{CardInCents: 92, SingleTripInCents: 170}},
+		{distance: 3.3, fare: FareAmount{CardInCents: 102, SingleTripInCents: 180}},
+		{distance: 45, fare: FareAmount{CardInCents: 202, SingleTripInCents: 270}}, // The last band has no limit
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.fare, fareTable.fare(testCase.distance).Adult, testCase.distance)
+	}
+	assert.Equal(t, FareAmount{CardInCents: 45, SingleTripInCents: 170}, fareTable.fare(1).Student)
+	assert.Equal(t, FareAmount{CardInCents: 65, SingleTripInCents: 170}, fareTable.fare(1).Senior)
+
+	errorCases := []struct {
+		fareTable string
+		err       error
+	}{
+		{fareTable: "Max Distance (km),Adult Card,Adult Single Trip,Student Card,Student Single Trip,Senior Card,Senior Single Trip\n", err: fmt.Errorf("no fare bands found in the fare table")},
+		{fareTable: "header,,,,,,\n3km,92,170,45,170,65,170\n", err: fmt.Errorf("invalid distance 3km in fare table")},
+		{fareTable: "header,,,,,,\n3.2,92,$1.70,45,170,65,170\n", err: fmt.Errorf("invalid fare $1.70 in fare table")},
+		{fareTable: "header,,,,,,\n4.2,102,180,50,180,72,180\n3.2,92,170,45,170,65,170\n", err: fmt.Errorf("fare table bands should be in ascending order of distance")},
+	}
+	for _, errorCase := range errorCases {
+		_, err := NewFareTable(strings.NewReader(errorCase.fareTable))
+		assert.Equal(t, errorCase.err, err)
+	}
+}
+
+func TestJourneyFare(t *testing.T) {
+	nw, err := NewEmbeddedNetwork(TrainLineTimeExceptionRules)
+	assert.Nil(t, err)
+	fareTable, err := NewEmbeddedFareTable()
+	assert.Nil(t, err)
+	distances, err := NewSegmentDistances(strings.NewReader("From Code,To Code,Distance (km)\nNS1,NS2,2.2\nns3,ns2,1.5\n"))
+	assert.Nil(t, err)
+	nw = nw.WithFares(fareTable, distances)
+
+	t.Run("calculates the fare from the distance travelled", func(t *testing.T) {
+		journeys, err := NewPlanner(nw).Plan(context.Background(), "NS1", "NS4", WithMaxRoutes(1))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(journeys))
+		assert.Equal(t, 4.9, journeys[0].Fare.DistanceInKm) // 2.2 + 1.5 + DEFAULT_SEGMENT_DISTANCE_IN_KM
+		assert.Equal(t, FareAmount{CardInCents: 112, SingleTripInCents: 190}, journeys[0].Fare.Adult)
+	})
+
+	t.Run("counts the stations passed through that aren't open yet", func(t *testing.T) {
+		// Canberra (NS12) isn't open in 2018 so the journey goes from NS11 to NS13 without stopping
+		journeys, err := NewPlanner(nw).Plan(context.Background(), "NS11", "NS13", WithMaxRoutes(1), WithNetworkDate(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"NS11", "NS13"}, journeys[0].StationCodes())
+		assert.Equal(t, 2.4, journeys[0].Fare.DistanceInKm)
+	})
+
+	t.Run("doesn't calculate the fare without a fare table", func(t *testing.T) {
+		nw, err := NewEmbeddedNetwork(TrainLineTimeExceptionRules)
+		assert.Nil(t, err)
+		journeys, err := NewPlanner(nw).Plan(context.Background(), "NS1", "NS4", WithMaxRoutes(1))
+		assert.Nil(t, err)
+		assert.Nil(t, journeys[0].Fare)
+	})
+
+	t.Run("orders the journeys by fare", func(t *testing.T) {
+		journeys, err := NewPlanner(nw).Plan(context.Background(), "Boon Lay", "Little India", WithMaxRoutes(MAX_ROUTES), WithSortByFare())
+		assert.Nil(t, err)
+		assert.Equal(t, MAX_ROUTES, len(journeys))
+		for idx := 1; idx < len(journeys); idx++ {
+			assert.LessOrEqual(t, journeys[idx-1].Fare.Adult.CardInCents, journeys[idx].Fare.Adult.CardInCents)
+		}
+	})
+
+	t.Run("returns an error for an invalid distance", func(t *testing.T) {
+		_, err := NewSegmentDistances(strings.NewReader("From Code,To Code,Distance (km)\nNS1,NS2,far\n"))
+		assert.Equal(t, fmt.Errorf("invalid distance far between NS1 and NS2"), err)
+	})
+}
@@ -0,0 +1,60 @@
+package routing
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// HolidayCalendar has the public holidays and special days of the network keyed by the date in DATE_FORMAT
+// The value is the name of the holiday which may be empty
+type HolidayCalendar map[string]string
+
+// LoadHolidayCalendar loads the holiday calendar from the csv file at the path
+func LoadHolidayCalendar(calendarPath string) (HolidayCalendar, error) {
+	calendarFile, err := os.Open(calendarPath)
+	if err != nil {
+		return nil, err
+	}
+	defer calendarFile.Close()
+	return NewHolidayCalendar(calendarFile)
+}
+
+// NewHolidayCalendar reads the holiday calendar from the csv reader
+// Each row has the date in DATE_FORMAT optionally followed by the name of the holiday e.g.
+/*
+# Lines starting with # are skipped
+2022-02-01,Chinese New Year
+2022-08-09,National Day
+*/
+func NewHolidayCalendar(r io.Reader) (HolidayCalendar, error) {
+	csvReader := csv.NewReader(r)
+	csvReader.Comment = '#'
+	csvReader.FieldsPerRecord = -1
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid holiday calendar file: %v", err)
+	}
+	calendar := HolidayCalendar{}
+	for _, record := range records {
+		date := strings.TrimSpace(record[0])
+		if _, err := time.Parse(DATE_FORMAT, date); err != nil {
+			return nil, fmt.Errorf("invalid holiday date %s", date)
+		}
+		var name string
+		if len(record) > 1 {
+			name = strings.TrimSpace(record[1])
+		}
+		calendar[date] = name
+	}
+	return calendar, nil
+}
+
+// IsHoliday checks whether the date of the time is a holiday
+func (c HolidayCalendar) IsHoliday(date time.Time) bool {
+	_, ok := c[date.Format(DATE_FORMAT)]
+	return ok
+}
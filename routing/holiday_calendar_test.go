@@ -0,0 +1,56 @@
+package routing
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHolidayCalendar(t *testing.T) {
+	t.Run("reads the holidays", func(t *testing.T) {
+		calendar, err := NewHolidayCalendar(strings.NewReader("# 2022 holidays\n2022-02-01,Chinese New Year\n2022-08-09\n"))
+		assert.Nil(t, err)
+		assert.Equal(t, HolidayCalendar{"2022-02-01": "Chinese New Year", "2022-08-09": ""}, calendar)
+		assert.True(t, calendar.IsHoliday(parseTestTime(t, "2022-02-01T08:00")))
+		assert.False(t, calendar.IsHoliday(parseTestTime(t, "2022-02-02T08:00")))
+	})
+
+	t.Run("returns an error for an invalid date", func(t *testing.T) {
+		_, err := NewHolidayCalendar(strings.NewReader("1 February 2022,Chinese New Year\n"))
+		assert.Equal(t, fmt.Errorf("invalid holiday date 1 February 2022"), err)
+	})
+}
+
+func TestHolidayTimeRules(t *testing.T) {
+	rules := TimeExceptionRule{
+		"NS": {Rules: []*TimeRule{
+			{Start: "6:00AM", End: "9:00AM", TrainLineMeta: TrainLineMeta{NextStationTimeInMinutes: 12, LineChangeTimeInMinutes: 15, DaysOfWeek: workingDays}},
+			{Start: "10:00AM", End: "6:00PM", TrainLineMeta: TrainLineMeta{NextStationTimeInMinutes: 6, LineChangeTimeInMinutes: 10, DayTypes: []string{HOLIDAY_DAY_TYPE, WEEKEND_DAY_TYPE}}},
+			{Start: "6:00PM", End: "9:00PM", TrainLineMeta: TrainLineMeta{NextStationTimeInMinutes: 20, LineChangeTimeInMinutes: 10, DayTypes: []string{"2022-02-02"}}},
+		}},
+		DEFAULT_KEY: {Default: &TrainLineMeta{NextStationTimeInMinutes: 10, LineChangeTimeInMinutes: 10}},
+	}
+	nw, err := NewEmbeddedNetwork(rules)
+	assert.Nil(t, err)
+	nw = nw.WithHolidayCalendar(HolidayCalendar{"2022-02-01": "Chinese New Year"})
+
+	testCases := []struct {
+		queryTime     string
+		estimatedTime int64
+	}{
+		{queryTime: "2022-01-31T08:00", estimatedTime: 12}, // Monday peak hours
+		{queryTime: "2022-02-01T08:00", estimatedTime: 10}, // The holiday overrides the weekday peak hours
+		{queryTime: "2022-02-01T12:00", estimatedTime: 6},  // Holiday rule
+		{queryTime: "2022-02-05T12:00", estimatedTime: 6},  // Weekend rule on a Saturday
+		{queryTime: "2022-02-04T12:00", estimatedTime: 10}, // Friday
+		{queryTime: "2022-02-02T19:00", estimatedTime: 20}, // Rule for the specific date
+		{queryTime: "2022-02-09T19:00", estimatedTime: 10},
+	}
+	for _, testCase := range testCases {
+		_, estimatedTime, _, err := getRouteEstimate(nw, "NS1", "NS2", parseTestTime(t, testCase.queryTime))
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.estimatedTime, estimatedTime, testCase.queryTime)
+	}
+}
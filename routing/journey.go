@@ -0,0 +1,214 @@
+package routing
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thoas/go-funk"
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+	"gitlab.myteksi.net/goscripts/zendesk/utils"
+)
+
+// Journey is a suggested route from the source to the destination station
+type Journey struct {
+	Stations               []*common.Station // The stations in the order of travel from the source to the destination
+	Instructions           []string          // The instructions for travelling between each pair of consecutive stations
+	StationsTravelled      int64
+	EstimatedTimeInMinutes int64                 // This is 0 if the journey was planned without a start or arrival time
+	TransferCount          int64                 // The number of line changes
+	Lines                  []string              // The train line codes in the order they are travelled on
+	DepartureTime          time.Time             // The start time, or the latest time to depart to arrive by the arrival time. Zero if planned without either
+	ArrivalTime            time.Time             // The time of arrival at the destination. Zero if planned without a start or arrival time
+	Shortest               bool                  // This will denote whether it's the shortest journey
+	Fare                   *Fare                 // The fare of the journey, nil if the network doesn't have a fare table
+	Disruptions            []*Disruption         // The active disruptions on the train lines of the journey
+	Legs                   []*Leg                // The parts of the journey on each train line in the order of travel
+	Coordinates            []*common.Coordinates // The locations of the stations, nil if the network doesn't have coordinates
+	StartWalk              *Walk                 // The walk from the start location to the source station, nil if the journey is planned from a station
+	EndWalk                *Walk                 // The walk from the destination station to the end location, nil if the journey is planned to a station
+	Summary                string                // The condensed instructions of the journey
+}
+
+// Leg is a part of the journey travelled on a single train line, or a walk between two nearby stations
+type Leg struct {
+	Line          string            // The train line code, empty for a walk
+	Walk          bool              // This will denote whether the leg is a walk between two nearby stations
+	Stations      []*common.Station // The stations of the leg in the order of travel from the board to the alight station
+	StartIndex    int               // The index of the first station of the leg in the stations of the journey
+	TimeInMinutes int64             // Including the time to change to the line. This is 0 if the journey was planned without a start or arrival time
+	Terminus      *common.Station   // The last station of the train line in the direction of travel, nil for a walk
+}
+
+// Stops returns the number of stations travelled on the leg, a walk doesn't travel any station
+func (l *Leg) Stops() int {
+	if l.Walk {
+		return 0
+	}
+	return len(l.Stations) - 1
+}
+
+// StationCodes returns the codes of the stations in the order of travel
+func (j *Journey) StationCodes() []string {
+	codes := make([]string, 0, len(j.Stations))
+	for _, station := range j.Stations {
+		codes = append(codes, station.Code)
+	}
+	return codes
+}
+
+// journeyLines returns the train line codes travelled on between the stations in the order of travel
+func journeyLines(nw *Network, stations []*common.Station) []string {
+	var lines []string
+	for idx := 0; idx+1 < len(stations); idx++ {
+		if nw.isTransfer(stations[idx], stations[idx+1]) {
+			continue
+		}
+		if lineCode := nw.rideLine(stations[idx].Code, stations[idx+1].Code); len(lines) == 0 || lines[len(lines)-1] != lineCode {
+			lines = append(lines, lineCode)
+		}
+	}
+	return lines
+}
+
+// journeyLegs splits the stations into the legs travelled on each train line, the line changes are between the legs
+// The time of each leg is from the end of the previous leg so that it includes the time to change to the line
+func journeyLegs(nw *Network, stations []*common.Station, elapsedTimes []int64, networkDate time.Time) []*Leg {
+	var legs []*Leg
+	var leg *Leg
+	for idx := 0; idx+1 < len(stations); idx++ {
+		station, nextStation := stations[idx], stations[idx+1]
+		switch {
+		case nw.isWalk(station, nextStation):
+			leg = &Leg{Walk: true, Stations: []*common.Station{station}, StartIndex: idx}
+			legs = append(legs, leg)
+		case isLineChange(station, nextStation):
+			leg = nil
+			continue
+		case leg == nil || leg.Walk || leg.Line != nw.rideLine(station.Code, nextStation.Code):
+			// A run through to another line starts a new leg at the station where the line changes
+			leg = &Leg{Line: nw.rideLine(station.Code, nextStation.Code), Stations: []*common.Station{station}, StartIndex: idx, Terminus: nw.terminus(station, nextStation, networkDate)}
+			legs = append(legs, leg)
+		}
+		leg.Stations = append(leg.Stations, nextStation)
+	}
+	previousEndIdx := 0
+	for _, leg := range legs {
+		endIdx := leg.StartIndex + len(leg.Stations) - 1
+		leg.TimeInMinutes = elapsedTimes[endIdx] - elapsedTimes[previousEndIdx]
+		previousEndIdx = endIdx
+	}
+	return legs
+}
+
+// journeySummary returns the condensed instructions of the journey e.g.
+// "Take EW line towards Pasir Ris for 6 stops to Buona Vista, change to CC line towards Dhoby Ghaut for 1 stop to Holland Village"
+func journeySummary(nw *Network, journey *Journey) string {
+	var parts []string
+	if journey.StartWalk != nil {
+		parts = append(parts, fmt.Sprintf("walk %d min to %s", journey.StartWalk.TimeInMinutes, journey.StartWalk.StationName))
+	}
+	for idx, leg := range journey.Legs {
+		lastStation := leg.Stations[len(leg.Stations)-1]
+		if leg.Walk {
+			walkingTime, _ := nw.walkingTime(leg.Stations[0].Code, lastStation.Code)
+			parts = append(parts, fmt.Sprintf("walk %d min to %s", walkingTime, lastStation.Name))
+			continue
+		}
+		action := "take"
+		if idx > 0 && !journey.Legs[idx-1].Walk {
+			action = "change to"
+			if previousStations := journey.Legs[idx-1].Stations; previousStations[len(previousStations)-1] == leg.Stations[0] {
+				action = "continue on" // The train runs through to the line
+			}
+		}
+		parts = append(parts, fmt.Sprintf("%s %s line towards %s for %s to %s", action, leg.Line, leg.Terminus.Name, pluralise(leg.Stops(), "stop"), lastStation.Name))
+	}
+	if journey.EndWalk != nil {
+		parts = append(parts, fmt.Sprintf("walk %d min to the destination", journey.EndWalk.TimeInMinutes))
+	}
+	summary := strings.Join(parts, ", ")
+	if summary == "" {
+		return summary
+	}
+	return strings.ToUpper(summary[:1]) + summary[1:]
+}
+
+// pluralise returns the count with the noun, which is plural unless the count is 1
+func pluralise(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// journeyCoordinates returns the locations of the stations, nil if the network doesn't have coordinates
+func journeyCoordinates(nw *Network, stations []*common.Station) []*common.Coordinates {
+	if nw.coordinates == nil {
+		return nil
+	}
+	coordinates := make([]*common.Coordinates, 0, len(stations))
+	for _, station := range stations {
+		coordinates = append(coordinates, nw.stationCoordinates(station.Code))
+	}
+	return coordinates
+}
+
+// generateStationList returns the stations of the route in the order of travel
+func generateStationList(routeNode *common.RouteNode) []*common.Station {
+	var stationPath []*common.Station
+	for _, node := range generateRouteNodeList(routeNode) {
+		stationPath = append(stationPath, node.Station)
+	}
+	return stationPath
+}
+
+func generateRouteNodeList(routeNode *common.RouteNode) []*common.RouteNode {
+	// traverse route as we have the a node in the middle so first we traverse backwards to get the
+	// first node and then traverse forward from the middle node to reach the end node and create an ordered list to create the path
+	nodePath := []*common.RouteNode{routeNode}
+	// traverse backwards
+	for startNode := routeNode.PrevNode; startNode != nil; startNode = startNode.PrevNode {
+		nodePath = append(nodePath, startNode)
+	}
+	nodePath = funk.Reverse(nodePath).([]*common.RouteNode)
+	// traverse forwards
+	for startNode := routeNode.NextNode; startNode != nil; startNode = startNode.NextNode {
+		nodePath = append(nodePath, startNode)
+	}
+	return nodePath
+}
+
+// elapsedTimes returns the time in minutes elapsed from the start of the journey to reach each of the route nodes in the order of travel
+// The route nodes of a backward search have the time elapsed from the end of the journey, which is subtracted from the total time
+func elapsedTimes(routeNodes []*common.RouteNode, backward bool) []int64 {
+	times := make([]int64, 0, len(routeNodes))
+	for _, routeNode := range routeNodes {
+		if backward {
+			times = append(times, routeNodes[0].EstimatedTime-routeNode.EstimatedTime)
+		} else {
+			times = append(times, routeNode.EstimatedTime)
+		}
+	}
+	return times
+}
+
+func generateVerboseRoute(nw *Network, startStation *common.Station, endStation *common.Station, networkDate time.Time) (string, error) {
+	startTrainLine, _, err := utils.GetStationMetadataFromCode(startStation.Code)
+	if err != nil {
+		return "", nil
+	}
+	endTrainLine, _, err := utils.GetStationMetadataFromCode(endStation.Code)
+	if err != nil {
+		return "", nil
+	}
+	// Not storing the format in a constant as this is the only place where it is used
+	if nw.isWalk(startStation, endStation) {
+		return fmt.Sprintf("Walk from %s to %s", nw.stationCodeNameMap[startStation.Code], nw.stationCodeNameMap[endStation.Code]), nil
+	}
+	if !isLineChange(startStation, endStation) {
+		return fmt.Sprintf("Take %s line from %s to %s towards %s", nw.rideLine(startStation.Code, endStation.Code), nw.stationCodeNameMap[startStation.Code], nw.stationCodeNameMap[endStation.Code], nw.terminus(startStation, endStation, networkDate).Name), nil
+	} else {
+		return fmt.Sprintf("Change from %s line to %s line", startTrainLine, endTrainLine), nil
+	}
+}
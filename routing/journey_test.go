@@ -0,0 +1,81 @@
+package routing
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJourneyLegs(t *testing.T) {
+	planner := NewPlanner(newTestNetwork(t))
+
+	t.Run("splits the journey into the legs on each train line", func(t *testing.T) {
+		for _, opt := range []PlanOption{WithStartTime(parseTestTime(t, "2022-01-31T08:00")), WithArriveBy(parseTestTime(t, "2022-01-31T09:00"))} {
+			journeys, err := planner.Plan(context.Background(), "Boon Lay", "Little India", opt)
+			assert.Nil(t, err)
+			journey := journeys[0]
+			assert.Equal(t, []string{"EW27", "EW26", "EW25", "EW24", "EW23", "EW22", "EW21", "CC22", "CC21", "CC20", "CC19", "DT9", "DT10", "DT11", "DT12"}, journey.StationCodes())
+			assert.Equal(t, "Take EW line towards Pasir Ris for 6 stops to Buona Vista, change to CC line towards Dhoby Ghaut for 3 stops to Botanic Gardens, change to DT line towards Expo for 3 stops to Little India", journey.Summary)
+			var lines []string
+			var stops int
+			var timeInMinutes int64
+			for _, leg := range journey.Legs {
+				lines = append(lines, leg.Line)
+				stops += leg.Stops()
+				timeInMinutes += leg.TimeInMinutes
+				assert.Equal(t, journey.Stations[leg.StartIndex:leg.StartIndex+len(leg.Stations)], leg.Stations)
+			}
+			assert.Equal(t, journey.Lines, lines)
+			assert.Equal(t, journey.StationsTravelled, int64(stops))
+			assert.Equal(t, journey.EstimatedTimeInMinutes, timeInMinutes) // The line changes are part of the legs
+		}
+	})
+
+	t.Run("summarises the walks of the journey", func(t *testing.T) {
+		walkingLinks, err := NewWalkingLinks(strings.NewReader("Station A,Station B,Minutes\nEsplanade,City Hall,7\n"))
+		assert.Nil(t, err)
+		nw, err := newTestNetwork(t).WithWalkingLinks(walkingLinks)
+		assert.Nil(t, err)
+		journeys, err := NewPlanner(nw).Plan(context.Background(), "Raffles Place", "Promenade", WithStartTime(parseTestTime(t, "2022-01-31T12:00")))
+		assert.Nil(t, err)
+		assert.Equal(t, "Take EW line towards Pasir Ris for 1 stop to City Hall, walk 7 min to Esplanade, take CC line towards HarbourFront for 1 stop to Promenade", journeys[0].Summary)
+		assert.Nil(t, journeys[0].Legs[1].Terminus)
+		assert.True(t, journeys[0].Legs[1].Walk)
+		assert.Equal(t, 0, journeys[0].Legs[1].Stops())
+		assert.Equal(t, int64(7), journeys[0].Legs[1].TimeInMinutes)
+	})
+}
+
+func TestTerminus(t *testing.T) {
+	nw := newTestNetwork(t)
+	networkDate := time.Date(2022, 1, 31, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		from        string
+		to          string
+		networkDate time.Time
+		terminus    string
+	}{
+		{from: "DT11", to: "DT12", networkDate: networkDate, terminus: "Expo"},
+		{from: "DT12", to: "DT11", networkDate: networkDate, terminus: "Bukit Panjang"},
+		{from: "CG1", to: "CG2", networkDate: networkDate, terminus: "Changi Airport"},
+		{from: "CG1", to: "CG0", networkDate: networkDate, terminus: "Tanah Merah"},
+		{from: "CE1", to: "CE0", networkDate: networkDate, terminus: "Promenade"},
+		{from: "CE0", to: "CE1", networkDate: networkDate, terminus: "Marina Bay"},
+		{from: "EW24", to: "EW22", networkDate: networkDate, terminus: "Pasir Ris"}, // Passing through a closed station
+		{from: "TE2", to: "TE3", networkDate: networkDate, terminus: "Gardens by the Bay"},
+		{from: "TE2", to: "TE3", networkDate: time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC), terminus: "Caldecott"}, // The stations after it weren't open
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.terminus, nw.terminus(nw.station(testCase.from), nw.station(testCase.to), testCase.networkDate).Name, testCase.from+" to "+testCase.to)
+	}
+
+	t.Run("shows the direction in the instructions", func(t *testing.T) {
+		journeys, err := NewPlanner(nw).Plan(context.Background(), "Newton", "Little India", WithNetworkDate(networkDate))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"Take DT line from Newton to Little India towards Expo"}, journeys[0].Instructions)
+		assert.Equal(t, "Expo", journeys[0].Legs[0].Terminus.Name)
+	})
+}
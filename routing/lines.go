@@ -0,0 +1,89 @@
+package routing
+
+import (
+	"sort"
+
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+	"gitlab.myteksi.net/goscripts/zendesk/utils"
+)
+
+// Lines returns the train lines ordered by code with their stations in the order of travel
+func (nw *Network) Lines() []*common.LineInfo {
+	lines := []*common.LineInfo{}
+	for lineCode := range nw.trainLine {
+		lines = append(lines, nw.lineInfo(lineCode))
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].Code < lines[j].Code
+	})
+	return lines
+}
+
+// Line returns the train line with its stations in the order of travel, false if the line isn't on the network
+func (nw *Network) Line(lineCode string) (*common.LineInfo, bool) {
+	if !nw.HasLine(lineCode) {
+		return nil, false
+	}
+	return nw.lineInfo(lineCode), true
+}
+
+// lineInfo walks the train line from its first station to the last station
+func (nw *Network) lineInfo(lineCode string) *common.LineInfo {
+	lineInfo := &common.LineInfo{Code: lineCode, Stations: []*common.LineStation{}, Interchanges: []string{}}
+	for _, station := range nw.lineStations(lineCode) {
+		lineStation := &common.LineStation{
+			Code:             station.Code,
+			Name:             station.Name,
+			OpeningDate:      station.OpeningDate,
+			InterchangeLines: []string{},
+			Coordinates:      nw.stationCoordinates(station.Code),
+		}
+		for _, linkedStation := range station.LinkedStations {
+			if !nw.isOnTrackLinks(linkedStation) {
+				continue
+			}
+			linkedLineCode, _, err := utils.GetStationMetadataFromCode(linkedStation.Code)
+			if err != nil {
+				continue
+			}
+			lineStation.InterchangeLines = append(lineStation.InterchangeLines, linkedLineCode)
+		}
+		if len(lineStation.InterchangeLines) > 0 {
+			lineInfo.Interchanges = append(lineInfo.Interchanges, station.Code)
+		}
+		lineInfo.Stations = append(lineInfo.Stations, lineStation)
+	}
+	lineInfo.StationCount = len(lineInfo.Stations)
+	if nw.hasTrackLinks() {
+		lineInfo.Termini = []string{}
+		for _, station := range nw.trackLineEnds(lineCode) {
+			lineInfo.Termini = append(lineInfo.Termini, station.Name)
+		}
+	} else if lineInfo.StationCount > 0 {
+		lineInfo.Termini = []string{lineInfo.Stations[0].Name, lineInfo.Stations[lineInfo.StationCount-1].Name}
+	}
+	return lineInfo
+}
+
+// lineStations returns the stations of the train line in the order of travel
+// With the track links only the stations that the trains run to or from are on the line
+func (nw *Network) lineStations(lineCode string) []*common.Station {
+	if nw.hasTrackLinks() {
+		return nw.trackLineStations(lineCode)
+	}
+	var stations []*common.Station
+	for station := nw.firstStation(lineCode); station != nil; station = station.NextStation {
+		stations = append(stations, station)
+	}
+	return stations
+}
+
+// firstStation returns the station on the train line which doesn't have a previous station
+func (nw *Network) firstStation(lineCode string) *common.Station {
+	for _, station := range nw.trainLine[lineCode] {
+		if station.PrevStation == nil {
+			return station
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLines(t *testing.T) {
+	nw := newTestNetwork(t)
+
+	t.Run("returns the lines ordered by code", func(t *testing.T) {
+		lines := nw.Lines()
+		assert.Equal(t, 8, len(lines))
+		assert.Equal(t, "CC", lines[0].Code)
+		assert.Equal(t, "TE", lines[7].Code)
+	})
+
+	t.Run("returns the stations of the line in the order of travel", func(t *testing.T) {
+		line, ok := nw.Line("CG")
+		assert.True(t, ok)
+		assert.Equal(t, 3, line.StationCount)
+		assert.Equal(t, []string{"Tanah Merah", "Changi Airport"}, line.Termini)
+		assert.Equal(t, "CG0", line.Stations[0].Code)
+		assert.Equal(t, "CG1", line.Stations[1].Code)
+		assert.Equal(t, "CG2", line.Stations[2].Code)
+		assert.Equal(t, []string{"EW"}, line.Stations[0].InterchangeLines)
+		assert.Equal(t, []string{"DT"}, line.Stations[1].InterchangeLines)
+		assert.Equal(t, []string{"CG0", "CG1"}, line.Interchanges)
+
+		line, ok = nw.Line("NS")
+		assert.True(t, ok)
+		assert.Equal(t, "NS5", line.Stations[4].Code)
+		assert.Equal(t, "NS7", line.Stations[5].Code)
+	})
+
+	t.Run("returns false for an unknown line", func(t *testing.T) {
+		_, ok := nw.Line("XX")
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,156 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/thoas/go-funk"
+)
+
+// weekdays are the valid values for DaysOfWeek in the time exception rules
+var weekdays = []string{
+	time.Sunday.String(), time.Monday.String(), time.Tuesday.String(), time.Wednesday.String(),
+	time.Thursday.String(), time.Friday.String(), time.Saturday.String(),
+}
+
+// LoadTimeExceptionRules loads and validates the time exception rules from the JSON file at the path
+// The file has the same structure as TrainLineTimeExceptionRules e.g.
+/*
+{
+	"DT": {
+		"Default": {"NextStationTimeInMinutes": 8, "LineChangeTimeInMinutes": 10},
+		"Rules": [
+			{"Start": "6:00AM", "End": "9:00AM", "NextStationTimeInMinutes": 10, "LineChangeTimeInMinutes": 15, "DaysOfWeek": ["Monday", "Tuesday"]},
+			{"Start": "10:00PM", "End": "6:00AM", "IsNotOperational": true, "DaysOfWeek": ["Monday", "Tuesday"]},
+			{"Start": "10:00AM", "End": "6:00PM", "Priority": 1, "NextStationTimeInMinutes": 6, "LineChangeTimeInMinutes": 10, "DayTypes": ["Holiday", "Weekend", "2022-12-24"]}
+		]
+	},
+	"default": {
+		"Default": {"NextStationTimeInMinutes": 10, "LineChangeTimeInMinutes": 10}
+	}
+}
+*/
+func LoadTimeExceptionRules(rulesPath string) (TimeExceptionRule, error) {
+	rulesFile, err := os.Open(rulesPath)
+	if err != nil {
+		return nil, err
+	}
+	defer rulesFile.Close()
+	rules := TimeExceptionRule{}
+	decoder := json.NewDecoder(rulesFile)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("invalid time rules file: %v", err)
+	}
+	if err := validateTimeExceptionRules(rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// validates that the rules can be evaluated by getRouteEstimate and that the rule to use is never ambiguous
+func validateTimeExceptionRules(rules TimeExceptionRule) error {
+	defaultLineTimeRules, ok := rules[DEFAULT_KEY]
+	if !ok || defaultLineTimeRules == nil {
+		return fmt.Errorf("missing %s train line config", DEFAULT_KEY)
+	}
+	if defaultLineTimeRules.Default == nil {
+		return fmt.Errorf("missing %s config in %s train line config", DEFAULT_KEY, DEFAULT_KEY)
+	}
+	for lineName, lineTimeRules := range rules {
+		if lineTimeRules == nil {
+			return fmt.Errorf("missing config for %s train line", lineName)
+		}
+		for _, timeRule := range lineTimeRules.Rules {
+			if timeRule == nil {
+				return fmt.Errorf("missing time rule of %s train line", lineName)
+			}
+			if _, _, err := timeRule.parseMinutes(); err != nil {
+				return fmt.Errorf("invalid time range %s of %s train line", timeRule, lineName)
+			}
+			for _, day := range timeRule.DaysOfWeek {
+				if !funk.ContainsString(weekdays, day) {
+					return fmt.Errorf("invalid day %s for time range %s of %s train line", day, timeRule, lineName)
+				}
+			}
+			for _, dayType := range timeRule.DayTypes {
+				if dayType == HOLIDAY_DAY_TYPE || dayType == WEEKEND_DAY_TYPE {
+					continue
+				}
+				if _, err := time.Parse(DATE_FORMAT, dayType); err != nil {
+					return fmt.Errorf("invalid day type %s for time range %s of %s train line", dayType, timeRule, lineName)
+				}
+			}
+		}
+		if err := validateTimeRuleOverlaps(lineName, lineTimeRules.Rules); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validates that no two rules with the same priority apply at the same time on any kind of day
+// The kinds of days are the days of the week and the dates in the rules, each of them either a holiday or not
+func validateTimeRuleOverlaps(lineName string, timeRules []*TimeRule) error {
+	days := ruleDays(timeRules)
+	for idx, timeRule := range timeRules {
+		for _, otherTimeRule := range timeRules[idx+1:] {
+			if timeRule.Priority != otherTimeRule.Priority {
+				continue
+			}
+			for _, day := range days {
+				for _, previousDay := range days {
+					if !isPreviousRuleDay(previousDay, day) {
+						continue
+					}
+					if isOverlapping(timeRule.activeMinutes(previousDay, day), otherTimeRule.activeMinutes(previousDay, day)) {
+						return fmt.Errorf("overlapping time ranges %s and %s of %s train line with the same priority", timeRule, otherTimeRule, lineName)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ruleDays returns the kinds of days on which the rules are evaluated
+func ruleDays(timeRules []*TimeRule) []ruleDay {
+	var days []ruleDay
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		days = append(days, ruleDay{weekday: weekday}, ruleDay{weekday: weekday, holiday: true})
+	}
+	for _, timeRule := range timeRules {
+		for _, dayType := range timeRule.DayTypes {
+			if date, err := time.Parse(DATE_FORMAT, dayType); err == nil {
+				days = append(days, ruleDay{weekday: date.Weekday(), date: dayType}, ruleDay{weekday: date.Weekday(), holiday: true, date: dayType})
+			}
+		}
+	}
+	return days
+}
+
+// isPreviousRuleDay checks whether the previous day can be the day before the day
+func isPreviousRuleDay(previousDay, day ruleDay) bool {
+	if previousDay.weekday != (day.weekday+6)%7 {
+		return false
+	}
+	if previousDay.date == "" || day.date == "" {
+		return true
+	}
+	previousDate, _ := time.Parse(DATE_FORMAT, previousDay.date)
+	return previousDate.AddDate(0, 0, 1).Format(DATE_FORMAT) == day.date
+}
+
+// isOverlapping checks whether any of the ranges of minutes overlap
+func isOverlapping(minutes, otherMinutes [][2]int) bool {
+	for _, minuteRange := range minutes {
+		for _, otherMinuteRange := range otherMinutes {
+			if minuteRange[0] < otherMinuteRange[1] && otherMinuteRange[0] < minuteRange[1] {
+				return true
+			}
+		}
+	}
+	return false
+}
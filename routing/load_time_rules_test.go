@@ -0,0 +1,136 @@
+package routing
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadTimeExceptionRules(t *testing.T) {
+	t.Run("loads the rules from the file", func(t *testing.T) {
+		rulesPath := filepath.Join(t.TempDir(), "rules.json")
+		err := ioutil.WriteFile(rulesPath, []byte(`{
+			"DT": {"Rules": [{"Start": "6:00AM", "End": "9:00AM", "NextStationTimeInMinutes": 20, "LineChangeTimeInMinutes": 25, "DaysOfWeek": ["Monday"]}]},
+			"default": {"Default": {"NextStationTimeInMinutes": 5, "LineChangeTimeInMinutes": 5}}
+		}`), 0644)
+		assert.Nil(t, err)
+
+		rules, err := LoadTimeExceptionRules(rulesPath)
+		assert.Nil(t, err)
+		assert.Equal(t, "6:00AM - 9:00AM", rules["DT"].Rules[0].String())
+		assert.Equal(t, int64(20), rules["DT"].Rules[0].NextStationTimeInMinutes)
+		assert.Equal(t, []string{"Monday"}, rules["DT"].Rules[0].DaysOfWeek)
+		assert.Equal(t, int64(5), rules[DEFAULT_KEY].Default.LineChangeTimeInMinutes)
+	})
+
+	t.Run("returns an error for an invalid file", func(t *testing.T) {
+		rulesPath := filepath.Join(t.TempDir(), "rules.json")
+		err := ioutil.WriteFile(rulesPath, []byte(`{"NS": {"Default": {"NextStationTimeInMinutes": 5}}}`), 0644)
+		assert.Nil(t, err)
+
+		_, err = LoadTimeExceptionRules(rulesPath)
+		assert.Equal(t, fmt.Errorf("missing default train line config"), err)
+	})
+}
+
+func TestValidateTimeExceptionRules(t *testing.T) {
+	defaultLineTimeRules := &LineTimeRules{Default: &TrainLineMeta{NextStationTimeInMinutes: 10, LineChangeTimeInMinutes: 10}}
+	testCases := []struct {
+		rules TimeExceptionRule
+		err   error
+	}{
+		{
+			rules: TrainLineTimeExceptionRules,
+			err:   nil,
+		},
+		{
+			rules: TimeExceptionRule{DEFAULT_KEY: defaultLineTimeRules},
+			err:   nil,
+		},
+		{
+			rules: TimeExceptionRule{"NS": defaultLineTimeRules},
+			err:   fmt.Errorf("missing default train line config"),
+		},
+		{
+			rules: TimeExceptionRule{DEFAULT_KEY: {Rules: []*TimeRule{{Start: "6:00AM", End: "9:00AM"}}}},
+			err:   fmt.Errorf("missing default config in default train line config"),
+		},
+		{
+			rules: TimeExceptionRule{
+				DEFAULT_KEY: defaultLineTimeRules,
+				"NS":        {Rules: []*TimeRule{{Start: "6AM", End: "9:00AM"}}},
+			},
+			err: fmt.Errorf("invalid time range 6AM - 9:00AM of NS train line"),
+		},
+		{
+			rules: TimeExceptionRule{
+				DEFAULT_KEY: defaultLineTimeRules,
+				"NS":        {Rules: []*TimeRule{{Start: "6:00AM", End: "9:00AM", TrainLineMeta: TrainLineMeta{DaysOfWeek: []string{"Monday", "Fri"}}}}},
+			},
+			err: fmt.Errorf("invalid day Fri for time range 6:00AM - 9:00AM of NS train line"),
+		},
+		{
+			rules: TimeExceptionRule{
+				DEFAULT_KEY: defaultLineTimeRules,
+				"NS":        {Rules: []*TimeRule{{Start: "6:00AM", End: "9:00AM", TrainLineMeta: TrainLineMeta{DayTypes: []string{HOLIDAY_DAY_TYPE, WEEKEND_DAY_TYPE, "2022-02-01"}}}}},
+			},
+			err: nil,
+		},
+		{
+			rules: TimeExceptionRule{
+				DEFAULT_KEY: defaultLineTimeRules,
+				"NS":        {Rules: []*TimeRule{{Start: "6:00AM", End: "9:00AM", TrainLineMeta: TrainLineMeta{DayTypes: []string{"Holidays"}}}}},
+			},
+			err: fmt.Errorf("invalid day type Holidays for time range 6:00AM - 9:00AM of NS train line"),
+		},
+		{
+			// The ranges that meet don't overlap as the end of a range is excluded
+			rules: TimeExceptionRule{
+				DEFAULT_KEY: defaultLineTimeRules,
+				"NS": {Rules: []*TimeRule{
+					{Start: "6:00AM", End: "9:00AM", TrainLineMeta: TrainLineMeta{DaysOfWeek: []string{"Monday"}}},
+					{Start: "9:00AM", End: "6:00AM", TrainLineMeta: TrainLineMeta{DaysOfWeek: []string{"Monday"}}},
+				}},
+			},
+			err: nil,
+		},
+		{
+			// The range that wraps past midnight on Sunday overlaps the range on Monday morning
+			rules: TimeExceptionRule{
+				DEFAULT_KEY: defaultLineTimeRules,
+				"NS": {Rules: []*TimeRule{
+					{Start: "6:00AM", End: "9:00AM", TrainLineMeta: TrainLineMeta{DaysOfWeek: []string{"Monday"}}},
+					{Start: "11:00PM", End: "7:00AM", TrainLineMeta: TrainLineMeta{DaysOfWeek: []string{"Sunday"}}},
+				}},
+			},
+			err: fmt.Errorf("overlapping time ranges 6:00AM - 9:00AM and 11:00PM - 7:00AM of NS train line with the same priority"),
+		},
+		{
+			// The holiday is considered as a Sunday for the days of the week
+			rules: TimeExceptionRule{
+				DEFAULT_KEY: defaultLineTimeRules,
+				"NS": {Rules: []*TimeRule{
+					{Start: "6:00AM", End: "9:00AM", TrainLineMeta: TrainLineMeta{DaysOfWeek: []string{"Sunday"}}},
+					{Start: "8:00AM", End: "10:00AM", TrainLineMeta: TrainLineMeta{DayTypes: []string{HOLIDAY_DAY_TYPE}}},
+				}},
+			},
+			err: fmt.Errorf("overlapping time ranges 6:00AM - 9:00AM and 8:00AM - 10:00AM of NS train line with the same priority"),
+		},
+		{
+			rules: TimeExceptionRule{
+				DEFAULT_KEY: defaultLineTimeRules,
+				"NS": {Rules: []*TimeRule{
+					{Start: "6:00AM", End: "9:00AM", TrainLineMeta: TrainLineMeta{DayTypes: []string{WEEKEND_DAY_TYPE}}},
+					{Start: "8:00AM", End: "10:00AM", Priority: 1, TrainLineMeta: TrainLineMeta{DayTypes: []string{"2022-12-24"}}},
+				}},
+			},
+			err: nil,
+		},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.err, validateTimeExceptionRules(testCase.rules))
+	}
+}
@@ -0,0 +1,128 @@
+package routing
+
+import (
+	"fmt"
+	"math"
+
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+)
+
+const (
+	WALKING_SPEED_IN_KM_PER_HOUR = 4.5 // Used to estimate the time to walk between a location and a station
+	MAX_WALKING_DISTANCE_IN_KM   = 2.0 // The stations further than this from a location aren't walked to or from
+	MAX_NEARBY_STATIONS          = 3   // The number of stations near a location that the journeys are planned from or to
+)
+
+// Walk is a walk between a location and a station at the start or the end of a journey
+type Walk struct {
+	StationName   string
+	DistanceInKm  float64 // The straight line distance between the location and the nearest platform of the station
+	TimeInMinutes int64
+}
+
+// stationAccess is a station that the journeys can start or end at, along with the walk between it and the location if
+// the journeys are planned from or to a location
+type stationAccess struct {
+	stationCodes []string
+	walk         *Walk // nil if the journeys are planned from or to the station itself
+}
+
+// NearestStations returns up to the limit stations nearest to the location ordered by the distance to their nearest platform
+// An error is returned if the network doesn't have station coordinates, which isn't an *InvalidRequestError as the request is valid
+func (nw *Network) NearestStations(location *common.Coordinates, limit int) ([]*common.NearbyStationInfo, error) {
+	if nw.coordinateIndex == nil {
+		return nil, fmt.Errorf("station coordinates aren't available")
+	}
+	stations := []*common.NearbyStationInfo{}
+	for _, nearbyStation := range nw.coordinateIndex.nearest(nw, location, limit, math.Inf(1)) {
+		stations = append(stations, &common.NearbyStationInfo{
+			StationInfo:          nw.stationInfo(nearbyStation.stationName),
+			DistanceInMeters:     int64(math.Round(nearbyStation.distanceInKm * 1000)),
+			WalkingTimeInMinutes: walkingTimeInMinutes(nearbyStation.distanceInKm),
+		})
+	}
+	return stations, nil
+}
+
+// walkingTimeInMinutes returns the time to walk the distance rounded up to a minute
+func walkingTimeInMinutes(distanceInKm float64) int64 {
+	return int64(math.Ceil(distanceInKm / WALKING_SPEED_IN_KM_PER_HOUR * 60))
+}
+
+// resolveStationAccesses returns the stations that the journeys can start or end at for the station name or code, or for the
+// location if there's one. The stations near the location are the ones open on the network date within MAX_WALKING_DISTANCE_IN_KM
+// The end is "source" or "destination" and is used in the error messages
+func resolveStationAccesses(nw *Network, station string, location *common.Coordinates, end string, options *planOptions) ([]*stationAccess, error) {
+	if location == nil {
+		stationCodes, ok := nw.resolveStationCodes(station)
+		if !ok {
+			return nil, &InvalidRequestError{Message: "invalid " + end + " station", Suggestions: nw.suggestStationNames(station)}
+		}
+		if !isAnyStationOpen(nw, stationCodes, options.networkDate) {
+			return nil, &InvalidRequestError{Message: end + " station is not open on " + options.networkDate.Format(DATE_FORMAT)}
+		}
+		return []*stationAccess{{stationCodes: stationCodes}}, nil
+	}
+	if nw.coordinateIndex == nil {
+		return nil, fmt.Errorf("station coordinates aren't available")
+	}
+	var accesses []*stationAccess
+	// The stations that aren't open are skipped, so the nearest stations are fetched without a limit
+	for _, nearbyStation := range nw.coordinateIndex.nearest(nw, location, len(nw.stationNameCodeMap), MAX_WALKING_DISTANCE_IN_KM) {
+		stationCodes := nw.stationNameCodeMap[nearbyStation.stationName]
+		if !isAnyStationOpen(nw, stationCodes, options.networkDate) {
+			continue
+		}
+		accesses = append(accesses, &stationAccess{
+			stationCodes: stationCodes,
+			walk: &Walk{
+				StationName:   nearbyStation.stationName,
+				DistanceInKm:  nearbyStation.distanceInKm,
+				TimeInMinutes: walkingTimeInMinutes(nearbyStation.distanceInKm),
+			},
+		})
+		if len(accesses) == MAX_NEARBY_STATIONS {
+			break
+		}
+	}
+	if len(accesses) == 0 {
+		return nil, &InvalidRequestError{Message: "no station within walking distance of the " + end + " location"}
+	}
+	return accesses, nil
+}
+
+// stationName returns the name of the station that the journeys start or end at
+func (a *stationAccess) stationName(nw *Network) string {
+	return nw.stationCodeNameMap[a.stationCodes[0]]
+}
+
+// withWalks returns a copy of the options to plan the journeys between the stations after walking to the start station and
+// before walking from the end station i.e. the start time is after the walk to the start station and the arrival time is before
+// the walk from the end station
+func (o *planOptions) withWalks(startWalk, endWalk *Walk) *planOptions {
+	walkOptions := *o
+	if startWalk != nil && !o.startTime.IsZero() {
+		walkOptions.startTime = elapsedQueryTime(o.startTime, startWalk.TimeInMinutes)
+	}
+	if endWalk != nil && !o.arriveBy.IsZero() {
+		walkOptions.arriveBy = elapsedQueryTime(o.arriveBy, -endWalk.TimeInMinutes)
+	}
+	return &walkOptions
+}
+
+// addWalks adds the walks to the start station and from the end station to the journey planned between the stations
+// The walking time is added to the estimated time, the departure time and the arrival time if the journey was planned with a time
+func (j *Journey) addWalks(startWalk, endWalk *Walk, options *planOptions) {
+	j.StartWalk, j.EndWalk = startWalk, endWalk
+	if !options.isTimed() {
+		return
+	}
+	if startWalk != nil {
+		j.EstimatedTimeInMinutes += startWalk.TimeInMinutes
+		j.DepartureTime = elapsedQueryTime(j.DepartureTime, -startWalk.TimeInMinutes)
+	}
+	if endWalk != nil {
+		j.EstimatedTimeInMinutes += endWalk.TimeInMinutes
+		j.ArrivalTime = elapsedQueryTime(j.ArrivalTime, endWalk.TimeInMinutes)
+	}
+}
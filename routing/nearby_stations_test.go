@@ -0,0 +1,126 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+)
+
+const testStationCoordinates = `Station Code,Latitude,Longitude
+EW10,1.3114,103.8714
+EW11,1.3073,103.8630
+EW12,1.3006,103.8559
+DT14,1.2990,103.8555
+EW13,1.2931,103.8520
+NS25,1.2931,103.8520
+EW14,1.2840,103.8515
+NS26,1.2840,103.8515
+EW15,1.2764,103.8455
+EW16,1.2803,103.8395
+EW27,1.3386,103.7060
+`
+
+// newTestCoordinatesNetwork builds the test network with the coordinates of the stations around the city
+func newTestCoordinatesNetwork(t *testing.T) *Network {
+	coordinates, err := NewStationCoordinates(strings.NewReader(testStationCoordinates))
+	assert.Nil(t, err)
+	nw, err := newTestNetwork(t).WithStationCoordinates(coordinates)
+	assert.Nil(t, err)
+	return nw
+}
+
+func TestNearestStations(t *testing.T) {
+	nw := newTestCoordinatesNetwork(t)
+
+	t.Run("returns the nearest stations ordered by the distance", func(t *testing.T) {
+		stations, err := nw.NearestStations(&common.Coordinates{Latitude: 1.2835, Longitude: 103.8510}, 3)
+		assert.Nil(t, err)
+		var names []string
+		for _, station := range stations {
+			names = append(names, station.Name)
+		}
+		assert.Equal(t, []string{"Raffles Place", "Tanjong Pagar", "City Hall"}, names)
+		assert.Equal(t, int64(79), stations[0].DistanceInMeters)
+		assert.Equal(t, int64(2), stations[0].WalkingTimeInMinutes)
+		assert.Equal(t, []string{"NS26", "EW14"}, stations[0].Codes)
+	})
+
+	t.Run("finds the same stations as comparing every station", func(t *testing.T) {
+		for latitude := 1.26; latitude < 1.36; latitude += 0.013 {
+			for longitude := 103.68; longitude < 103.9; longitude += 0.017 {
+				location := &common.Coordinates{Latitude: latitude, Longitude: longitude}
+				var expected []*nearbyStation
+				for stationName, stationCodes := range nw.stationNameCodeMap {
+					var nearest *nearbyStation
+					for _, stationCode := range stationCodes {
+						coordinates := nw.stationCoordinates(stationCode)
+						if coordinates != nil && (nearest == nil || haversineDistance(location, coordinates) < nearest.distanceInKm) {
+							nearest = &nearbyStation{stationName: stationName, distanceInKm: haversineDistance(location, coordinates)}
+						}
+					}
+					if nearest != nil {
+						expected = append(expected, nearest)
+					}
+				}
+				sort.Slice(expected, func(i, j int) bool { return expected[i].distanceInKm < expected[j].distanceInKm })
+				assert.Equal(t, expected[:4], nw.coordinateIndex.nearest(nw, location, 4, 100))
+			}
+		}
+	})
+
+	t.Run("returns an error without station coordinates", func(t *testing.T) {
+		_, err := newTestNetwork(t).NearestStations(&common.Coordinates{Latitude: 1.2835, Longitude: 103.8510}, 3)
+		assert.Equal(t, fmt.Errorf("station coordinates aren't available"), err)
+	})
+}
+
+func TestPlanFromLocation(t *testing.T) {
+	nw := newTestCoordinatesNetwork(t)
+	planner := NewPlanner(nw, WithLocation(time.UTC))
+	nearRafflesPlace := &common.Coordinates{Latitude: 1.2835, Longitude: 103.8510}
+
+	t.Run("walks to the station nearest to the start location", func(t *testing.T) {
+		startTime := time.Date(2022, 1, 31, 12, 0, 0, 0, time.UTC)
+		journeys, err := planner.Plan(context.Background(), "", "Lavender", WithFromLocation(nearRafflesPlace), WithStartTime(startTime))
+		assert.Nil(t, err)
+		assert.Equal(t, &Walk{StationName: "Raffles Place", DistanceInKm: journeys[0].StartWalk.DistanceInKm, TimeInMinutes: 2}, journeys[0].StartWalk)
+		assert.Nil(t, journeys[0].EndWalk)
+		assert.Equal(t, []string{"EW14", "EW13", "EW12", "EW11"}, journeys[0].StationCodes())
+		stationJourneys, err := planner.Plan(context.Background(), "Raffles Place", "Lavender", WithStartTime(startTime.Add(2*time.Minute)))
+		assert.Nil(t, err)
+		assert.Equal(t, stationJourneys[0].EstimatedTimeInMinutes+2, journeys[0].EstimatedTimeInMinutes)
+		assert.Equal(t, startTime, journeys[0].DepartureTime)
+		assert.Equal(t, stationJourneys[0].ArrivalTime, journeys[0].ArrivalTime)
+	})
+
+	t.Run("walks from the station nearest to the end location to arrive by the time", func(t *testing.T) {
+		arriveBy := time.Date(2022, 1, 31, 12, 0, 0, 0, time.UTC)
+		journeys, err := planner.Plan(context.Background(), "Lavender", "", WithToLocation(nearRafflesPlace), WithArriveBy(arriveBy))
+		assert.Nil(t, err)
+		assert.Equal(t, "Raffles Place", journeys[0].EndWalk.StationName)
+		assert.Equal(t, arriveBy, journeys[0].ArrivalTime)
+		assert.Equal(t, arriveBy.Add(-time.Duration(journeys[0].EstimatedTimeInMinutes)*time.Minute), journeys[0].DepartureTime)
+	})
+
+	t.Run("doesn't start at the avoided stations", func(t *testing.T) {
+		journeys, err := planner.Plan(context.Background(), "", "Lavender", WithFromLocation(nearRafflesPlace), WithAvoidStations("Raffles Place"))
+		assert.Nil(t, err)
+		for _, journey := range journeys {
+			assert.NotEqual(t, "Raffles Place", journey.StartWalk.StationName)
+			assert.NotContains(t, journey.StationCodes(), "EW14")
+		}
+	})
+
+	t.Run("returns an error if there's no station within walking distance", func(t *testing.T) {
+		_, err := planner.Plan(context.Background(), "", "Lavender", WithFromLocation(&common.Coordinates{Latitude: 1.4, Longitude: 103.8}))
+		assert.Equal(t, &InvalidRequestError{Message: "no station within walking distance of the source location"}, err)
+		_, err = NewPlanner(newTestNetwork(t)).Plan(context.Background(), "", "Lavender", WithFromLocation(nearRafflesPlace))
+		assert.Equal(t, fmt.Errorf("station coordinates aren't available"), err)
+	})
+}
@@ -0,0 +1,118 @@
+package routing
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// NetworkStore holds the network in use and allows it to be swapped with a freshly built network
+// The requests that are in flight keep using the network that they started with
+type NetworkStore struct {
+	network     atomic.Value // holds the *Network in use
+	reloadMutex sync.Mutex   // ensures that only one reload builds a network at a time
+}
+
+// NewNetworkStore returns a store with the network in use
+func NewNetworkStore(nw *Network) *NetworkStore {
+	store := &NetworkStore{}
+	store.network.Store(nw)
+	return store
+}
+
+// Network returns the network in use
+func (s *NetworkStore) Network() *Network {
+	return s.network.Load().(*Network)
+}
+
+// Reload builds a fresh network using the loader and swaps it in
+// If the loader fails the network in use is kept and the error is returned
+func (s *NetworkStore) Reload(loader func() (*Network, error)) error {
+	s.reloadMutex.Lock()
+	defer s.reloadMutex.Unlock()
+	nw, err := loader()
+	if err != nil {
+		return err
+	}
+	s.network.Store(nw)
+	return nil
+}
+
+// LoadNetworkFromEnv builds the network from the files configured in the env variables
+// STATION_MAP_PATH is the path to the station map csv, the embedded station map is used if it isn't defined
+// TIME_RULES_PATH is the path to the time exception rules json, the compiled-in TrainLineTimeExceptionRules are used if it isn't defined
+// HOLIDAY_CALENDAR_PATH is the path to the holiday calendar csv, there are no holidays if it isn't defined
+// FARE_TABLE_PATH is the path to the fare table csv, the embedded fare table is used if it isn't defined
+// SEGMENT_DISTANCES_PATH is the path to the segment distances csv, DEFAULT_SEGMENT_DISTANCE_IN_KM is used for every segment if it isn't defined
+// TRACK_LINKS_PATH is the path to the track links csv, the trains run between the stations with consecutive numbers on each line if it isn't defined
+// WALKING_LINKS_PATH is the path to the walking links csv, the routes don't walk between stations if it isn't defined
+// STATION_COORDINATES_PATH is the path to the station coordinates csv, the stations don't have coordinates if it isn't defined
+func LoadNetworkFromEnv() (*Network, error) {
+	rules := TrainLineTimeExceptionRules
+	if rulesPath := os.Getenv("TIME_RULES_PATH"); rulesPath != "" {
+		var err error
+		rules, err = LoadTimeExceptionRules(rulesPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var holidays HolidayCalendar
+	if calendarPath := os.Getenv("HOLIDAY_CALENDAR_PATH"); calendarPath != "" {
+		var err error
+		holidays, err = LoadHolidayCalendar(calendarPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	fareTable, err := NewEmbeddedFareTable()
+	if fareTablePath := os.Getenv("FARE_TABLE_PATH"); fareTablePath != "" {
+		fareTable, err = LoadFareTable(fareTablePath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var distances SegmentDistances
+	if distancesPath := os.Getenv("SEGMENT_DISTANCES_PATH"); distancesPath != "" {
+		distances, err = LoadSegmentDistances(distancesPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var nw *Network
+	if stationMapPath := os.Getenv("STATION_MAP_PATH"); stationMapPath != "" {
+		nw, err = NewNetworkFromFile(stationMapPath, rules)
+	} else {
+		nw, err = NewEmbeddedNetwork(rules)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if trackLinksPath := os.Getenv("TRACK_LINKS_PATH"); trackLinksPath != "" {
+		trackLinks, err := LoadTrackLinks(trackLinksPath)
+		if err != nil {
+			return nil, err
+		}
+		if nw, err = nw.WithTrackLinks(trackLinks); err != nil {
+			return nil, err
+		}
+	}
+	if walkingLinksPath := os.Getenv("WALKING_LINKS_PATH"); walkingLinksPath != "" {
+		walkingLinks, err := LoadWalkingLinks(walkingLinksPath)
+		if err != nil {
+			return nil, err
+		}
+		if nw, err = nw.WithWalkingLinks(walkingLinks); err != nil {
+			return nil, err
+		}
+	}
+	if coordinatesPath := os.Getenv("STATION_COORDINATES_PATH"); coordinatesPath != "" {
+		coordinates, err := LoadStationCoordinates(coordinatesPath)
+		if err != nil {
+			return nil, err
+		}
+		if nw, err = nw.WithStationCoordinates(coordinates); err != nil {
+			return nil, err
+		}
+	}
+	return nw.WithHolidayCalendar(holidays).WithFares(fareTable, distances), nil
+}
@@ -0,0 +1,65 @@
+package routing
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkStore(t *testing.T) {
+	t.Run("swaps in a fresh network", func(t *testing.T) {
+		oldNetwork := newTestNetwork(t)
+		store := NewNetworkStore(oldNetwork)
+		assert.Nil(t, store.Reload(func() (*Network, error) {
+			return NewEmbeddedNetwork(TrainLineTimeExceptionRules)
+		}))
+		assert.NotSame(t, oldNetwork, store.Network())
+		assert.Equal(t, len(oldNetwork.stationCodeNameMap), len(store.Network().stationCodeNameMap))
+	})
+
+	t.Run("keeps the network in use if the new network is invalid", func(t *testing.T) {
+		oldNetwork := newTestNetwork(t)
+		store := NewNetworkStore(oldNetwork)
+		err := store.Reload(func() (*Network, error) {
+			return NewNetworkFromFile("missing.csv", TrainLineTimeExceptionRules)
+		})
+		assert.NotNil(t, err)
+		assert.Same(t, oldNetwork, store.Network())
+	})
+
+	t.Run("keeps the network in use if the disruptions aren't valid on the new network", func(t *testing.T) {
+		oldNetwork := newTestNetwork(t)
+		store := NewNetworkStore(oldNetwork)
+		disruptionStore, err := NewDisruptionStore("")
+		assert.Nil(t, err)
+		_, err = disruptionStore.Add(&Disruption{Type: STATION_DISRUPTION, Station: "Clementi", Start: time.Date(2022, 1, 29, 5, 0, 0, 0, time.UTC)})
+		assert.Nil(t, err)
+		err = store.Reload(disruptionStore.NetworkLoader(func() (*Network, error) {
+			return NewNetwork(strings.NewReader("Station Code,Station Name,Opening Date\nEW1,Pasir Ris,\nEW2,Tampines,\n"), TrainLineTimeExceptionRules)
+		}))
+		assert.Equal(t, fmt.Errorf("invalid disruption 1: invalid station Clementi of disruption"), err)
+		assert.Same(t, oldNetwork, store.Network())
+
+		assert.Nil(t, store.Reload(disruptionStore.NetworkLoader(func() (*Network, error) {
+			return NewEmbeddedNetwork(TrainLineTimeExceptionRules)
+		})))
+		assert.NotSame(t, oldNetwork, store.Network())
+	})
+
+	t.Run("builds the network from the env variables", func(t *testing.T) {
+		t.Setenv("STATION_MAP_PATH", "")
+		t.Setenv("TIME_RULES_PATH", "")
+		nw, err := LoadNetworkFromEnv()
+		assert.Nil(t, err)
+		stationCount, lineCount := nw.Stats()
+		assert.Equal(t, 166, stationCount)
+		assert.Equal(t, 8, lineCount)
+
+		t.Setenv("STATION_MAP_PATH", "missing.csv")
+		_, err = LoadNetworkFromEnv()
+		assert.Equal(t, fmt.Errorf("couldn't open the csv file: open missing.csv: no such file or directory"), err)
+	})
+}
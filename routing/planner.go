@@ -0,0 +1,442 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+)
+
+// Planner plans the journeys between stations on the network provided for each plan
+type Planner struct {
+	networks    NetworkProvider
+	location    *time.Location     // The timezone in which the time rules are evaluated
+	clock       func() time.Time   // Returns the current time
+	disruptions DisruptionProvider // The disruptions that the journeys are planned around, nil if there are none
+}
+
+// PlannerOption configures the planner
+type PlannerOption func(planner *Planner)
+
+// WithLocation evaluates the time rules in the timezone, DEFAULT_NETWORK_TIMEZONE is used if it isn't provided
+func WithLocation(location *time.Location) PlannerOption {
+	return func(planner *Planner) {
+		planner.location = location
+	}
+}
+
+// WithClock uses the clock to get the current time instead of the system clock
+func WithClock(clock func() time.Time) PlannerOption {
+	return func(planner *Planner) {
+		planner.clock = clock
+	}
+}
+
+// WithDisruptions plans the journeys around the disruptions active at the time they are travelled
+func WithDisruptions(disruptions DisruptionProvider) PlannerOption {
+	return func(planner *Planner) {
+		planner.disruptions = disruptions
+	}
+}
+
+// NewPlanner returns a planner over the networks
+// A *Network can be passed to plan on a fixed network or a *NetworkStore to plan on the network in use
+func NewPlanner(networks NetworkProvider, opts ...PlannerOption) *Planner {
+	planner := &Planner{networks: networks, location: defaultNetworkLocation(), clock: time.Now}
+	for _, opt := range opts {
+		opt(planner)
+	}
+	return planner
+}
+
+// Location returns the timezone in which the time rules are evaluated
+func (p *Planner) Location() *time.Location {
+	return p.location
+}
+
+// Now returns the current time in the network timezone
+func (p *Planner) Now() time.Time {
+	return p.clock().In(p.location)
+}
+
+// planOptions has the options that a journey is planned with
+type planOptions struct {
+	startTime         time.Time // The time at which the journey starts. The estimated time isn't calculated if it is zero
+	arriveBy          time.Time // The time by which the journey has to end. The journeys are planned backwards from it if it isn't zero
+	networkDate       time.Time // The date as of which the network is considered
+	maxRoutes         int       // The maximum number of journeys to plan
+	sortByFare        bool      // The journeys are ordered by the adult card fare instead of their cost
+	location          *time.Location
+	now               func() time.Time
+	disruptions       []*Disruption
+	avoidStations     []string            // The station names or codes that the journeys don't go through
+	avoidLines        []string            // The train line codes that the journeys don't travel on
+	via               string              // The station name or code that the journeys go through
+	avoidStationNames map[string]bool     // The names of the avoided stations resolved on the network of the plan
+	viaCodes          []string            // The codes of the via station resolved on the network of the plan
+	optimize          string              // OPTIMIZE_TIME, OPTIMIZE_STATIONS or OPTIMIZE_TRANSFERS, the time is optimized if there's a start or arrival time else the stations if it is empty
+	maxTransfers      int                 // The maximum number of line changes in a journey, there's no limit if it is negative
+	fromLocation      *common.Coordinates // The location the journeys start from instead of the source station
+	toLocation        *common.Coordinates // The location the journeys end at instead of the destination station
+}
+
+// isTimed checks whether the journeys are planned with a start or arrival time, the estimated time is only calculated if so
+func (o *planOptions) isTimed() bool {
+	return !o.startTime.IsZero() || !o.arriveBy.IsZero()
+}
+
+// PlanOption configures how a journey is planned
+type PlanOption func(options *planOptions)
+
+// WithStartTime plans the journeys starting at the time, the estimated time of the journeys is only calculated with a start time
+func WithStartTime(startTime time.Time) PlanOption {
+	return func(options *planOptions) {
+		options.startTime = startTime
+	}
+}
+
+// WithArriveBy plans the journeys arriving at the destination by the time and the latest time to depart for each of them
+// It can't be used along with the start time
+func WithArriveBy(arriveBy time.Time) PlanOption {
+	return func(options *planOptions) {
+		options.arriveBy = arriveBy
+	}
+}
+
+// WithNetworkDate plans the journeys on the network as of the date
+// If it isn't provided the date of the start or arrival time is used, else the current date
+func WithNetworkDate(networkDate time.Time) PlanOption {
+	return func(options *planOptions) {
+		options.networkDate = networkDate
+	}
+}
+
+// WithMaxRoutes plans at most the given number of journeys, DEFAULT_MAX_ROUTES journeys are planned if it isn't provided
+// It should be between 1 and MAX_ROUTES
+func WithMaxRoutes(maxRoutes int) PlanOption {
+	return func(options *planOptions) {
+		options.maxRoutes = maxRoutes
+	}
+}
+
+// WithSortByFare orders the planned journeys by the adult card fare, the journeys with the same fare keep their order
+// The journeys aren't reordered if the network doesn't have a fare table
+func WithSortByFare() PlanOption {
+	return func(options *planOptions) {
+		options.sortByFare = true
+	}
+}
+
+// WithAvoidStations plans the journeys that don't go through any platform of the stations
+func WithAvoidStations(stations ...string) PlanOption {
+	return func(options *planOptions) {
+		options.avoidStations = append(options.avoidStations, stations...)
+	}
+}
+
+// WithAvoidLines plans the journeys that don't travel on or change to the train lines
+func WithAvoidLines(lineCodes ...string) PlanOption {
+	return func(options *planOptions) {
+		options.avoidLines = append(options.avoidLines, lineCodes...)
+	}
+}
+
+// WithVia plans the journeys that go through the station
+func WithVia(station string) PlanOption {
+	return func(options *planOptions) {
+		options.via = station
+	}
+}
+
+// WithOptimize plans the journeys with the least time (OPTIMIZE_TIME), stations (OPTIMIZE_STATIONS) or line changes (OPTIMIZE_TRANSFERS)
+// The time can only be optimized with a start or arrival time
+func WithOptimize(optimize string) PlanOption {
+	return func(options *planOptions) {
+		options.optimize = optimize
+	}
+}
+
+// WithMaxTransfers plans the journeys that change lines at most the given number of times
+func WithMaxTransfers(maxTransfers int) PlanOption {
+	return func(options *planOptions) {
+		options.maxTransfers = maxTransfers
+	}
+}
+
+// WithFromLocation plans the journeys from the location instead of the source station, starting with a walk to one of the
+// stations near the location
+func WithFromLocation(location *common.Coordinates) PlanOption {
+	return func(options *planOptions) {
+		options.fromLocation = location
+	}
+}
+
+// WithToLocation plans the journeys to the location instead of the destination station, ending with a walk from one of the
+// stations near the location
+func WithToLocation(location *common.Coordinates) PlanOption {
+	return func(options *planOptions) {
+		options.toLocation = location
+	}
+}
+
+// withPlanner plans in the timezone, with the clock and around the disruptions of the planner
+func withPlanner(planner *Planner) PlanOption {
+	return func(options *planOptions) {
+		options.location = planner.location
+		options.now = planner.clock
+		if planner.disruptions != nil {
+			options.disruptions = planner.disruptions.Disruptions()
+		}
+	}
+}
+
+// newPlanOptions applies the options over the defaults
+// The start and arrival times are converted to the network timezone so that the time rules are evaluated in it
+func newPlanOptions(opts []PlanOption) *planOptions {
+	options := &planOptions{maxRoutes: DEFAULT_MAX_ROUTES, location: time.UTC, now: time.Now, maxTransfers: -1}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if !options.startTime.IsZero() {
+		options.startTime = options.startTime.In(options.location)
+	}
+	if !options.arriveBy.IsZero() {
+		options.arriveBy = options.arriveBy.In(options.location)
+	}
+	if options.optimize == "" {
+		options.optimize = OPTIMIZE_STATIONS
+		if options.isTimed() {
+			options.optimize = OPTIMIZE_TIME
+		}
+	}
+	if options.networkDate.IsZero() {
+		networkDate := options.startTime
+		if networkDate.IsZero() {
+			networkDate = options.arriveBy
+		}
+		if networkDate.IsZero() {
+			networkDate = options.now().In(options.location)
+		}
+		options.networkDate = time.Date(networkDate.Year(), networkDate.Month(), networkDate.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return options
+}
+
+// hasRouteConstraints checks whether the journeys are planned with any stations or train lines to avoid or a station to go through
+func (o *planOptions) hasRouteConstraints() bool {
+	return len(o.avoidStations) > 0 || len(o.avoidLines) > 0 || o.via != ""
+}
+
+// networkDay returns the start and the end of the network date in the network timezone
+func (o *planOptions) networkDay() (time.Time, time.Time) {
+	startTime := time.Date(o.networkDate.Year(), o.networkDate.Month(), o.networkDate.Day(), 0, 0, 0, 0, o.location)
+	return startTime, startTime.AddDate(0, 0, 1)
+}
+
+// InvalidRequestError is returned when a journey can't be planned for the request e.g. the station is unknown
+type InvalidRequestError struct {
+	Message     string
+	Suggestions []string // The station names that may have been meant if the station is unknown
+}
+
+func (e *InvalidRequestError) Error() string {
+	return e.Message
+}
+
+// Plan returns the journeys from the source to the destination station ordered by the estimated time if there's a start or
+// arrival time else the number of stations, unless the line changes or the stations are optimized instead. The first journey is the shortest one and the rest are the next shortest alternatives
+// unless they are sorted by fare
+// The stations can either be the station names or the station codes. If a station code is given the journey starts or ends on the platform of that line
+// With a location to start from or end at, the journeys are planned from or to the stations near it with a walk to or from them,
+// and the station name is ignored
+// An *InvalidRequestError is returned if the stations or train lines to avoid or the station to go through make the journey impossible
+func (p *Planner) Plan(ctx context.Context, from, to string, opts ...PlanOption) ([]*Journey, error) {
+	// The same network is used throughout the plan even if the network is reloaded meanwhile
+	nw := p.networks.Network()
+	options := newPlanOptions(append([]PlanOption{withPlanner(p)}, opts...))
+	if !options.startTime.IsZero() && !options.arriveBy.IsZero() {
+		return nil, &InvalidRequestError{Message: "start time and arrive by time can't be used together"}
+	}
+	if options.maxRoutes < 1 || options.maxRoutes > MAX_ROUTES {
+		return nil, &InvalidRequestError{Message: fmt.Sprintf("max routes should be between 1 and %d", MAX_ROUTES)}
+	}
+	if options.optimize != OPTIMIZE_TIME && options.optimize != OPTIMIZE_STATIONS && options.optimize != OPTIMIZE_TRANSFERS {
+		return nil, &InvalidRequestError{Message: "invalid optimize " + options.optimize}
+	}
+	if options.optimize == OPTIMIZE_TIME && !options.isTimed() {
+		return nil, &InvalidRequestError{Message: "time can only be optimized with a start time or arrive by time"}
+	}
+	sources, err := resolveStationAccesses(nw, from, options.fromLocation, "source", options)
+	if err != nil {
+		return nil, err
+	}
+	destinations, err := resolveStationAccesses(nw, to, options.toLocation, "destination", options)
+	if err != nil {
+		return nil, err
+	}
+	if err := resolveRouteConstraints(nw, sources, destinations, options); err != nil {
+		return nil, err
+	}
+
+	var journeys []*Journey
+	for _, source := range sources {
+		for _, destination := range destinations {
+			if !options.isAllowedAccess(nw, source) || !options.isAllowedAccess(nw, destination) {
+				continue
+			}
+			if source.walk != nil && destination.walk != nil && source.stationName(nw) == destination.stationName(nw) {
+				continue // The locations are near the same station
+			}
+			walkOptions := options.withWalks(source.walk, destination.walk)
+			routes, err := fetchRoutes(ctx, nw, source.stationCodes, destination.stationCodes, walkOptions)
+			if err != nil {
+				return nil, err
+			}
+			for _, routeNode := range routes {
+				journey, err := generateJourney(nw, routeNode, walkOptions)
+				if err != nil {
+					return nil, err
+				}
+				journey.addWalks(source.walk, destination.walk, options)
+				journey.Summary = journeySummary(nw, journey)
+				journeys = append(journeys, journey)
+			}
+		}
+	}
+	if len(journeys) == 0 && options.hasRouteConstraints() {
+		return nil, &InvalidRequestError{Message: "no route found that satisfies the avoid and via constraints"}
+	}
+	if len(journeys) == 0 && options.maxTransfers >= 0 {
+		return nil, &InvalidRequestError{Message: fmt.Sprintf("no route found with at most %d transfers", options.maxTransfers)}
+	}
+	return rankJourneys(nw, journeys, options), nil
+}
+
+// resolveRouteConstraints resolves the stations to avoid and to go through on the network and checks that they don't conflict with the source
+// and destination stations
+// The stations near a location aren't checked as the journeys just don't start or end at them if they are avoided or the via station
+func resolveRouteConstraints(nw *Network, sources, destinations []*stationAccess, options *planOptions) error {
+	endNames := map[string]bool{}
+	for _, access := range append(append([]*stationAccess{}, sources...), destinations...) {
+		if access.walk == nil {
+			endNames[access.stationName(nw)] = true
+		}
+	}
+	options.avoidStationNames = map[string]bool{}
+	for _, station := range options.avoidStations {
+		stationCodes, ok := nw.resolveStationCodes(station)
+		if !ok {
+			return &InvalidRequestError{Message: "invalid avoid station " + station, Suggestions: nw.suggestStationNames(station)}
+		}
+		stationName := nw.stationCodeNameMap[stationCodes[0]]
+		if endNames[stationName] {
+			return &InvalidRequestError{Message: "source and destination stations can't be avoided"}
+		}
+		options.avoidStationNames[stationName] = true
+	}
+	for _, lineCode := range options.avoidLines {
+		if !nw.HasLine(lineCode) {
+			return &InvalidRequestError{Message: "invalid avoid line " + lineCode}
+		}
+	}
+	if options.via == "" {
+		return nil
+	}
+	viaCodes, ok := nw.resolveStationCodes(options.via)
+	if !ok {
+		return &InvalidRequestError{Message: "invalid via station", Suggestions: nw.suggestStationNames(options.via)}
+	}
+	viaName := nw.stationCodeNameMap[viaCodes[0]]
+	if endNames[viaName] {
+		return &InvalidRequestError{Message: "via station should be different from the source and destination stations"}
+	}
+	if options.avoidStationNames[viaName] {
+		return &InvalidRequestError{Message: "via station can't be avoided"}
+	}
+	if !isAnyStationOpen(nw, viaCodes, options.networkDate) {
+		return &InvalidRequestError{Message: "via station is not open on " + options.networkDate.Format(DATE_FORMAT)}
+	}
+	options.viaCodes = viaCodes
+	return nil
+}
+
+// isAllowedAccess checks whether the journeys can start or end at the station i.e. it isn't avoided and isn't the via station
+func (o *planOptions) isAllowedAccess(nw *Network, access *stationAccess) bool {
+	stationName := access.stationName(nw)
+	return !o.avoidStationNames[stationName] && (len(o.viaCodes) == 0 || stationName != nw.stationCodeNameMap[o.viaCodes[0]])
+}
+
+// generateJourney generates the journey from the route node of the end station of the route
+func generateJourney(nw *Network, routeNode *common.RouteNode, options *planOptions) (*Journey, error) {
+	routeNodes := generateRouteNodeList(routeNode)
+	stations := generateStationList(routeNode)
+	var instructions []string
+	for idx, station := range stations {
+		if idx+1 != len(stations) { // Skip the instruction for last node as it would be covered with previous node's instruction
+			instruction, err := generateVerboseRoute(nw, station, stations[idx+1], options.networkDate)
+			if err != nil {
+				return nil, err
+			}
+			instructions = append(instructions, instruction)
+		}
+	}
+	journey := &Journey{
+		Stations:               stations,
+		Instructions:           instructions,
+		StationsTravelled:      routeNode.StationCount,
+		EstimatedTimeInMinutes: routeNode.EstimatedTime,
+		TransferCount:          routeNode.TransferCount,
+		Lines:                  journeyLines(nw, stations),
+		Fare:                   nw.journeyFare(stations),
+		Legs:                   journeyLegs(nw, stations, elapsedTimes(routeNodes, !options.arriveBy.IsZero()), options.networkDate),
+		Coordinates:            journeyCoordinates(nw, stations),
+	}
+	if !options.startTime.IsZero() {
+		journey.DepartureTime = options.startTime
+		journey.ArrivalTime = elapsedQueryTime(options.startTime, routeNode.EstimatedTime)
+	} else if !options.arriveBy.IsZero() {
+		journey.DepartureTime = elapsedQueryTime(options.arriveBy, -routeNode.EstimatedTime)
+		journey.ArrivalTime = options.arriveBy
+	}
+	if len(options.disruptions) > 0 {
+		startTime, endTime := options.networkDay()
+		if options.isTimed() {
+			startTime, endTime = journey.DepartureTime, journey.ArrivalTime.Add(time.Minute)
+		}
+		journey.Disruptions = journeyDisruptions(nw, activeDisruptions(options.disruptions, startTime, endTime), stations)
+	}
+	return journey, nil
+}
+
+// rankJourneys orders the journeys by their cost, keeps up to the max routes of them and marks the shortest journeys
+// The shortest journeys are the ones with the least time, stations or line changes based on what is optimized
+// The journeys are then reordered by their fare if they are sorted by fare
+func rankJourneys(nw *Network, journeys []*Journey, options *planOptions) []*Journey {
+	// The journeys between a pair of stations are already ordered, so only the journeys planned from or to a location are reordered
+	sort.SliceStable(journeys, func(i, j int) bool {
+		return journeyCost(journeys[i], options).less(journeyCost(journeys[j], options))
+	})
+	if len(journeys) > options.maxRoutes {
+		journeys = journeys[:options.maxRoutes]
+	}
+	for _, journey := range journeys {
+		journey.Shortest = journeyCost(journey, options).primary == journeyCost(journeys[0], options).primary
+	}
+	if options.sortByFare && nw.fareTable != nil {
+		sort.SliceStable(journeys, func(i, j int) bool {
+			return journeys[i].Fare.Adult.CardInCents < journeys[j].Fare.Adult.CardInCents
+		})
+	}
+	return journeys
+}
+
+// journeyCost returns the cost of the journey including the walks to and from the stations
+func journeyCost(journey *Journey, options *planOptions) routeCost {
+	return getRouteCost(&common.RouteNode{
+		StationCount:  journey.StationsTravelled,
+		EstimatedTime: journey.EstimatedTimeInMinutes,
+		TransferCount: journey.TransferCount,
+	}, options)
+}
@@ -0,0 +1,252 @@
+package routing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlan(t *testing.T) {
+	planner := NewPlanner(newTestNetwork(t), WithLocation(time.UTC)) // The test times are the times in the network timezone
+
+	t.Run("plans the journeys ordered by the estimated time", func(t *testing.T) {
+		journeys, err := planner.Plan(context.Background(), "Boon Lay", "Little India", WithStartTime(parseTestTime(t, "2022-01-31T08:00")))
+		assert.Nil(t, err)
+		assert.NotEmpty(t, journeys)
+		assert.True(t, journeys[0].Shortest)
+		for idx, journey := range journeys {
+			assert.Equal(t, "EW27", journey.Stations[0].Code)
+			assert.Equal(t, len(journey.Stations)-1, len(journey.Instructions))
+			if idx > 0 {
+				assert.True(t, journeys[idx-1].EstimatedTimeInMinutes <= journey.EstimatedTimeInMinutes)
+			}
+		}
+	})
+
+	t.Run("plans the journeys arriving by the time", func(t *testing.T) {
+		arriveBy := parseTestTime(t, "2022-01-31T09:00")
+		journeys, err := planner.Plan(context.Background(), "Boon Lay", "Little India", WithArriveBy(arriveBy))
+		assert.Nil(t, err)
+		assert.NotEmpty(t, journeys)
+		assert.True(t, journeys[0].Shortest)
+		for idx, journey := range journeys {
+			assert.Equal(t, "EW27", journey.Stations[0].Code)
+			assert.Equal(t, "Little India", journey.Stations[len(journey.Stations)-1].Name)
+			assert.Equal(t, arriveBy, journey.ArrivalTime)
+			assert.Equal(t, arriveBy.Add(-time.Duration(journey.EstimatedTimeInMinutes)*time.Minute), journey.DepartureTime)
+			if idx > 0 {
+				assert.True(t, journeys[idx-1].DepartureTime.After(journey.DepartureTime) || journeys[idx-1].DepartureTime.Equal(journey.DepartureTime))
+			}
+		}
+
+		_, err = planner.Plan(context.Background(), "Boon Lay", "Little India", WithStartTime(arriveBy), WithArriveBy(arriveBy))
+		assert.Equal(t, &InvalidRequestError{Message: "start time and arrive by time can't be used together"}, err)
+	})
+
+	t.Run("returns an error for an invalid max routes", func(t *testing.T) {
+		for _, maxRoutes := range []int{-1, 0, MAX_ROUTES + 1} {
+			_, err := planner.Plan(context.Background(), "Boon Lay", "Little India", WithMaxRoutes(maxRoutes))
+			assert.Equal(t, &InvalidRequestError{Message: "max routes should be between 1 and 10"}, err)
+		}
+	})
+
+	t.Run("departs in time to arrive by the time when the time rules change on the way", func(t *testing.T) {
+		for _, arriveBy := range []string{"2022-01-31T09:10", "2022-01-31T21:30"} {
+			journeys, err := planner.Plan(context.Background(), "Boon Lay", "Little India", WithArriveBy(parseTestTime(t, arriveBy)))
+			assert.Nil(t, err)
+			for _, journey := range journeys {
+				_, estimatedTime, isNotOperational, err := estimateRoute(planner.networks.Network(), journey.Stations, journey.DepartureTime)
+				assert.Nil(t, err)
+				assert.False(t, isNotOperational)
+				assert.Equal(t, journey.EstimatedTimeInMinutes, estimatedTime, arriveBy)
+			}
+		}
+	})
+
+	t.Run("evaluates the time rules in the network timezone", func(t *testing.T) {
+		singaporePlanner := NewPlanner(planner.networks)
+		// 00:30 UTC is 8:30AM in Singapore which is in the peak hours
+		journeys, err := singaporePlanner.Plan(context.Background(), "NS1", "NS4", WithStartTime(parseTestTime(t, "2022-01-31T00:30")), WithMaxRoutes(1))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3*12), journeys[0].EstimatedTimeInMinutes)
+		assert.Equal(t, "2022-01-31T08:30:00+08:00", journeys[0].DepartureTime.Format(time.RFC3339))
+
+		journeys, err = planner.Plan(context.Background(), "NS1", "NS4", WithStartTime(parseTestTime(t, "2022-01-31T00:30")), WithMaxRoutes(1))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3*10), journeys[0].EstimatedTimeInMinutes)
+	})
+
+	t.Run("uses the date of the clock in the network timezone", func(t *testing.T) {
+		// It is already 31 December 2021 in Singapore when Gardens by the Bay opened
+		clockPlanner := NewPlanner(planner.networks, WithClock(func() time.Time { return parseTestTime(t, "2021-12-30T16:30") }))
+		_, err := clockPlanner.Plan(context.Background(), "Bishan", "Gardens by the Bay")
+		assert.Nil(t, err)
+		assert.Equal(t, "2021-12-31T00:30", clockPlanner.Now().Format("2006-01-02T15:04"))
+
+		clockPlanner = NewPlanner(planner.networks, WithLocation(time.UTC), WithClock(func() time.Time { return parseTestTime(t, "2021-12-30T16:30") }))
+		_, err = clockPlanner.Plan(context.Background(), "Bishan", "Gardens by the Bay")
+		assert.Equal(t, &InvalidRequestError{Message: "destination station is not open on 2021-12-30"}, err)
+	})
+
+	t.Run("pins the journey to the platform of the station code", func(t *testing.T) {
+		journeys, err := planner.Plan(context.Background(), "ew24", "Choa Chu Kang")
+		assert.Nil(t, err)
+		assert.NotEmpty(t, journeys)
+		for _, journey := range journeys {
+			assert.Equal(t, "EW24", journey.Stations[0].Code)
+		}
+		assert.Equal(t, "NS1", journeys[0].Stations[1].Code)
+		assert.Equal(t, "Change from EW line to NS line", journeys[0].Instructions[0])
+
+		journeys, err = planner.Plan(context.Background(), "Dhoby Ghaut", "CC4")
+		assert.Nil(t, err)
+		assert.NotEmpty(t, journeys)
+		for _, journey := range journeys {
+			assert.Equal(t, "CC4", journey.Stations[len(journey.Stations)-1].Code)
+		}
+	})
+
+	t.Run("validates the stations", func(t *testing.T) {
+		testCases := []struct {
+			from string
+			to   string
+			opts []PlanOption
+			err  error
+		}{
+			{
+				from: "Marina Bay",
+				to:   "Bishan",
+				opts: []PlanOption{WithStartTime(parseTestTime(t, "2019-01-31T08:00"))},
+				err:  nil, // Marina Bay is open on NS and CE lines
+			},
+			{
+				from: "Bishan",
+				to:   "Gardens by the Bay",
+				opts: []PlanOption{WithStartTime(parseTestTime(t, "2019-01-31T08:00"))},
+				err:  &InvalidRequestError{Message: "destination station is not open on 2019-01-31"},
+			},
+			{
+				from: "Gardens by the Bay",
+				to:   "Bishan",
+				opts: []PlanOption{WithNetworkDate(time.Date(2021, 12, 31, 0, 0, 0, 0, time.UTC))},
+				err:  nil,
+			},
+			{
+				from: "Bishan",
+				to:   "Dhoby Gaut",
+				err:  &InvalidRequestError{Message: "invalid destination station", Suggestions: []string{"Dhoby Ghaut"}},
+			},
+			{
+				from: "harbourfront",
+				to:   "Bishan",
+				err:  &InvalidRequestError{Message: "invalid source station", Suggestions: []string{"HarbourFront"}},
+			},
+		}
+		for _, testCase := range testCases {
+			_, err := planner.Plan(context.Background(), testCase.from, testCase.to, testCase.opts...)
+			assert.Equal(t, testCase.err, err)
+		}
+	})
+
+	t.Run("plans the journeys around the avoided stations and lines", func(t *testing.T) {
+		journeys, err := planner.Plan(context.Background(), "Boon Lay", "Little India", WithMaxRoutes(MAX_ROUTES), WithAvoidStations("Dhoby Ghaut", "CC19"), WithAvoidLines("DT"))
+		assert.Nil(t, err)
+		assert.NotEmpty(t, journeys)
+		for _, journey := range journeys {
+			for _, station := range journey.Stations {
+				assert.NotEqual(t, "Dhoby Ghaut", station.Name)
+				assert.NotEqual(t, "Botanic Gardens", station.Name) // All the platforms of an avoided station code are avoided
+				assert.NotEqual(t, "DT", station.Code[:2])
+			}
+		}
+	})
+
+	t.Run("plans the journeys through the via station", func(t *testing.T) {
+		for _, opt := range []PlanOption{WithStartTime(parseTestTime(t, "2022-01-31T08:00")), WithArriveBy(parseTestTime(t, "2022-01-31T09:00"))} {
+			journeys, err := planner.Plan(context.Background(), "Boon Lay", "Little India", WithVia("Bishan"), opt)
+			assert.Nil(t, err)
+			assert.NotEmpty(t, journeys)
+			for idx, journey := range journeys {
+				stationNames := map[string]bool{}
+				for _, station := range journey.Stations {
+					stationNames[station.Name] = true
+				}
+				assert.True(t, stationNames["Bishan"])
+				assert.Equal(t, "Boon Lay", journey.Stations[0].Name)
+				assert.Equal(t, "Little India", journey.Stations[len(journey.Stations)-1].Name)
+				if idx > 0 {
+					assert.True(t, journeys[idx-1].EstimatedTimeInMinutes <= journey.EstimatedTimeInMinutes)
+				}
+			}
+		}
+	})
+
+	t.Run("validates the avoid and via constraints", func(t *testing.T) {
+		testCases := []struct {
+			opts []PlanOption
+			err  error
+		}{
+			{opts: []PlanOption{WithAvoidLines("EW")}, err: &InvalidRequestError{Message: "no route found that satisfies the avoid and via constraints"}},
+			{opts: []PlanOption{WithAvoidStations("Little India")}, err: &InvalidRequestError{Message: "source and destination stations can't be avoided"}},
+			{opts: []PlanOption{WithAvoidStations("Dhoby Gaut")}, err: &InvalidRequestError{Message: "invalid avoid station Dhoby Gaut", Suggestions: []string{"Dhoby Ghaut"}}},
+			{opts: []PlanOption{WithAvoidLines("XX")}, err: &InvalidRequestError{Message: "invalid avoid line XX"}},
+			{opts: []PlanOption{WithVia("EW27")}, err: &InvalidRequestError{Message: "via station should be different from the source and destination stations"}},
+			{opts: []PlanOption{WithVia("Bishan"), WithAvoidStations("Bishan")}, err: &InvalidRequestError{Message: "via station can't be avoided"}},
+		}
+		for _, testCase := range testCases {
+			_, err := planner.Plan(context.Background(), "Boon Lay", "Little India", testCase.opts...)
+			assert.Equal(t, testCase.err, err)
+		}
+	})
+
+	t.Run("plans the journeys with the fewest line changes", func(t *testing.T) {
+		journeys, err := planner.Plan(context.Background(), "Boon Lay", "Little India", WithOptimize(OPTIMIZE_TRANSFERS), WithStartTime(parseTestTime(t, "2022-01-31T08:00")))
+		assert.Nil(t, err)
+		assert.NotEmpty(t, journeys)
+		assert.Equal(t, int64(1), journeys[0].TransferCount)
+		assert.Equal(t, 2, len(journeys[0].Lines))
+		assert.Equal(t, "EW", journeys[0].Lines[0])
+		for idx, journey := range journeys {
+			assert.Equal(t, journey.TransferCount == 1, journey.Shortest)
+			if idx > 0 {
+				assert.True(t, journeys[idx-1].TransferCount <= journey.TransferCount)
+			}
+		}
+	})
+
+	t.Run("limits the line changes", func(t *testing.T) {
+		journeys, err := planner.Plan(context.Background(), "Boon Lay", "Little India", WithMaxTransfers(1), WithMaxRoutes(MAX_ROUTES), WithStartTime(parseTestTime(t, "2022-01-31T08:00")))
+		assert.Nil(t, err)
+		assert.NotEmpty(t, journeys)
+		for idx, journey := range journeys {
+			assert.True(t, journey.TransferCount <= 1)
+			if idx > 0 {
+				assert.True(t, journeys[idx-1].EstimatedTimeInMinutes <= journey.EstimatedTimeInMinutes)
+			}
+		}
+	})
+
+	t.Run("validates what is optimized", func(t *testing.T) {
+		testCases := []struct {
+			opts []PlanOption
+			err  error
+		}{
+			{opts: []PlanOption{WithOptimize("price")}, err: &InvalidRequestError{Message: "invalid optimize price"}},
+			{opts: []PlanOption{WithOptimize(OPTIMIZE_TIME)}, err: &InvalidRequestError{Message: "time can only be optimized with a start time or arrive by time"}},
+			{opts: []PlanOption{WithMaxTransfers(0)}, err: &InvalidRequestError{Message: "no route found with at most 0 transfers"}},
+		}
+		for _, testCase := range testCases {
+			_, err := planner.Plan(context.Background(), "Boon Lay", "Little India", testCase.opts...)
+			assert.Equal(t, testCase.err, err)
+		}
+	})
+
+	t.Run("stops planning when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := planner.Plan(ctx, "Boon Lay", "Little India")
+		assert.Equal(t, context.Canceled, err)
+	})
+}
@@ -0,0 +1,80 @@
+package routing
+
+import (
+	"container/heap"
+
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+)
+
+// routeCost is the cost of reaching a route node that the routes are ordered by
+type routeCost struct {
+	primary   int64 // The estimated time, the number of stations travelled or the number of line changes based on what is optimized
+	secondary int64 // Breaks the ties between routes with the same primary cost
+}
+
+func (c routeCost) less(other routeCost) bool {
+	if c.primary != other.primary {
+		return c.primary < other.primary
+	}
+	return c.secondary < other.secondary
+}
+
+// getRouteCost returns the cost of reaching the route node based on what is optimized in the plan
+// The ties between the routes with the same number of line changes are broken by the estimated time if there's a start or
+// arrival time else the number of stations
+func getRouteCost(routeNode *common.RouteNode, options *planOptions) routeCost {
+	switch options.optimize {
+	case OPTIMIZE_TRANSFERS:
+		if !options.isTimed() {
+			return routeCost{primary: routeNode.TransferCount, secondary: routeNode.StationCount}
+		}
+		return routeCost{primary: routeNode.TransferCount, secondary: routeNode.EstimatedTime}
+	case OPTIMIZE_STATIONS:
+		return routeCost{primary: routeNode.StationCount, secondary: routeNode.EstimatedTime}
+	}
+	return routeCost{primary: routeNode.EstimatedTime, secondary: routeNode.StationCount}
+}
+
+type queuedRouteNode struct {
+	routeNode *common.RouteNode
+	cost      routeCost
+	order     int // The order in which the node was queued so that nodes with the same cost are popped in a stable order
+}
+
+// routeQueue is the priority queue of the route nodes ordered by their cost used by the shortest route search
+type routeQueue struct {
+	items     []*queuedRouteNode
+	nextOrder int
+}
+
+func (q *routeQueue) Len() int { return len(q.items) }
+
+func (q *routeQueue) Less(i, j int) bool {
+	if q.items[i].cost != q.items[j].cost {
+		return q.items[i].cost.less(q.items[j].cost)
+	}
+	return q.items[i].order < q.items[j].order
+}
+
+func (q *routeQueue) Swap(i, j int) { q.items[i], q.items[j] = q.items[j], q.items[i] }
+
+func (q *routeQueue) Push(item interface{}) {
+	q.items = append(q.items, item.(*queuedRouteNode))
+}
+
+func (q *routeQueue) Pop() interface{} {
+	item := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return item
+}
+
+// push queues the route node with its cost
+func (q *routeQueue) push(routeNode *common.RouteNode, cost routeCost) {
+	heap.Push(q, &queuedRouteNode{routeNode: routeNode, cost: cost, order: q.nextOrder})
+	q.nextOrder++
+}
+
+// pop returns the queued route node with the lowest cost
+func (q *routeQueue) pop() *common.RouteNode {
+	return heap.Pop(q).(*queuedRouteNode).routeNode
+}
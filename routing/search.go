@@ -0,0 +1,590 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+
+	"github.com/thoas/go-funk"
+	"gitlab.myteksi.net/goscripts/zendesk/utils"
+)
+
+// routeConstraints has the stations and segments that a route search can't use
+type routeConstraints struct {
+	blockedStations     map[string]bool // Keyed by the station code
+	blockedStationNames map[string]bool // Keyed by the station name so that none of the platforms of the station are used
+	blockedSegments     map[string]bool // Keyed by the segment key of the stations travelled between
+	blockedLines        map[string]bool // Keyed by the train line code so that none of the stations of the line are used
+}
+
+// newRouteConstraints returns the constraints that block the stations and train lines avoided in the plan
+func (s *routeSearch) newRouteConstraints() *routeConstraints {
+	constraints := &routeConstraints{blockedStations: map[string]bool{}, blockedStationNames: map[string]bool{}, blockedSegments: map[string]bool{}, blockedLines: map[string]bool{}}
+	for stationName := range s.options.avoidStationNames {
+		constraints.blockedStationNames[stationName] = true
+	}
+	for _, lineCode := range s.options.avoidLines {
+		constraints.blockedLines[lineCode] = true
+	}
+	return constraints
+}
+
+func (c *routeConstraints) isStationBlocked(station *common.Station) bool {
+	return c.blockedStations[station.Code] || c.blockedStationNames[station.Name] || c.blockedLines[station.Code[:2]]
+}
+
+// segmentKey returns the key of the segment between the station codes in the order of the search
+// The start code is empty for the segment to the station the search starts from
+func segmentKey(startStationCode, endStationCode string) string {
+	return startStationCode + "-" + endStationCode
+}
+
+// routeSearch finds the routes between the source and destination station codes on the network
+// The search starts from the source station, or from the destination station when it runs backwards from the arrival time
+type routeSearch struct {
+	nw         *Network
+	startCodes []string        // The station codes the search starts from
+	endCodes   map[string]bool // The station codes the search ends at
+	backward   bool            // Whether the search runs backwards from the destination station
+	options    *planOptions
+}
+
+// fetchRoutes finds up to the max routes between the source and destination station codes ordered by their cost
+// The cost is the estimated time if there's a start or arrival time else the number of stations travelled.
+// The first route is found with Dijkstra's algorithm so it is always an optimal route, and the alternatives are the next
+// cheapest loopless routes found with Yen's algorithm. Each segment is evaluated at the time it is travelled i.e. the
+// start time plus the time elapsed so far, and segments that are not operational at that time are never used.
+// If there's an arrival time the search runs backwards from the destination station with the clock going back from the
+// arrival time, and the route nodes of the source station are returned which are linked to the next nodes in the route
+// The routes never go through the stations or train lines avoided in the plan, and go through the via station if there's one
+func fetchRoutes(ctx context.Context, nw *Network, sourceCodes, destinationCodes []string, options *planOptions) ([]*common.RouteNode, error) {
+	search := &routeSearch{nw: nw, startCodes: sourceCodes, endCodes: map[string]bool{}, options: options}
+	if !options.arriveBy.IsZero() {
+		search.backward = true
+		search.startCodes, destinationCodes = destinationCodes, sourceCodes
+	}
+	for _, endCode := range destinationCodes {
+		search.endCodes[endCode] = true
+	}
+	if len(options.viaCodes) > 0 {
+		return search.viaRoutes(ctx)
+	}
+	return search.routes(ctx)
+}
+
+// routes finds up to the max routes from the station codes the search starts from to the station codes it ends at ordered by their cost
+func (s *routeSearch) routes(ctx context.Context) ([]*common.RouteNode, error) {
+	constraints := s.newRouteConstraints()
+	startNodes, err := s.startNodes(constraints)
+	if err != nil {
+		return nil, err
+	}
+	shortestRoute, err := s.shortestRoute(ctx, startNodes, constraints)
+	if err != nil || shortestRoute == nil {
+		return nil, err
+	}
+	routes := []*common.RouteNode{shortestRoute}
+	routeKeys := map[string]bool{s.routeKey(shortestRoute): true}
+	var candidateRoutes []*common.RouteNode
+	for len(routes) < s.options.maxRoutes {
+		previousRoute := s.routeNodeList(routes[len(routes)-1])
+		// Every candidate deviates from the previous route at the spur node, -1 denotes deviating at the station the search starts from
+		for spurIdx := -1; spurIdx+1 < len(previousRoute); spurIdx++ {
+			candidateRoute, err := s.spurRoute(ctx, routes, previousRoute, spurIdx)
+			if err != nil {
+				return nil, err
+			}
+			if candidateRoute == nil || routeKeys[s.routeKey(candidateRoute)] {
+				continue
+			}
+			routeKeys[s.routeKey(candidateRoute)] = true
+			candidateRoutes = append(candidateRoutes, candidateRoute)
+		}
+		if len(candidateRoutes) == 0 {
+			break // There are no more routes
+		}
+		cheapestIdx := 0
+		for idx, candidateRoute := range candidateRoutes {
+			if getRouteCost(candidateRoute, s.options).less(getRouteCost(candidateRoutes[cheapestIdx], s.options)) {
+				cheapestIdx = idx
+			}
+		}
+		routes = append(routes, candidateRoutes[cheapestIdx])
+		candidateRoutes = append(candidateRoutes[:cheapestIdx], candidateRoutes[cheapestIdx+1:]...)
+	}
+	return routes, nil
+}
+
+// viaRoutes finds up to the max routes that go through the via station ordered by their cost
+// The routes to the via station are found first, and each of them is continued with the cheapest route from the via station to
+// the station codes the search ends at that doesn't go back to the stations already visited
+func (s *routeSearch) viaRoutes(ctx context.Context) ([]*common.RouteNode, error) {
+	viaSearch := &routeSearch{nw: s.nw, startCodes: s.startCodes, endCodes: map[string]bool{}, backward: s.backward, options: s.options}
+	for _, viaCode := range s.options.viaCodes {
+		viaSearch.endCodes[viaCode] = true
+	}
+	// The route to the via station can't go through the station the search ends at as it would have to be visited again
+	viaSearchOptions := *s.options
+	viaSearchOptions.avoidStationNames = map[string]bool{}
+	for stationName := range s.options.avoidStationNames {
+		viaSearchOptions.avoidStationNames[stationName] = true
+	}
+	for endCode := range s.endCodes {
+		viaSearchOptions.avoidStationNames[s.nw.stationCodeNameMap[endCode]] = true
+	}
+	viaSearch.options = &viaSearchOptions
+	viaRoutes, err := viaSearch.routes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var routes []*common.RouteNode
+	for _, viaRoute := range viaRoutes {
+		constraints := s.newRouteConstraints()
+		for _, routeNode := range s.routeNodeList(viaRoute) {
+			if routeNode.Station.Name != viaRoute.Station.Name {
+				constraints.blockedStationNames[routeNode.Station.Name] = true
+			}
+		}
+		route, err := s.shortestRoute(ctx, []*common.RouteNode{viaRoute}, constraints)
+		if err != nil {
+			return nil, err
+		}
+		if route != nil {
+			routes = append(routes, route)
+		}
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return getRouteCost(routes[i], s.options).less(getRouteCost(routes[j], s.options))
+	})
+	return routes, nil
+}
+
+// spurRoute finds the cheapest route that is the same as the previous route up to the spur node and then deviates from
+// all the routes found so far that share the same stations up to the spur node
+func (s *routeSearch) spurRoute(ctx context.Context, routes []*common.RouteNode, previousRoute []*common.RouteNode, spurIdx int) (*common.RouteNode, error) {
+	constraints := s.newRouteConstraints()
+	for _, route := range routes {
+		routeNodes := s.routeNodeList(route)
+		if len(routeNodes) <= spurIdx+1 || !isSameRoute(routeNodes[:spurIdx+1], previousRoute[:spurIdx+1]) {
+			continue
+		}
+		if spurIdx < 0 {
+			constraints.blockedSegments[segmentKey("", routeNodes[0].Station.Code)] = true
+		} else {
+			constraints.blockedSegments[segmentKey(routeNodes[spurIdx].Station.Code, routeNodes[spurIdx+1].Station.Code)] = true
+		}
+	}
+	if spurIdx < 0 {
+		startNodes, err := s.startNodes(constraints)
+		if err != nil {
+			return nil, err
+		}
+		return s.shortestRoute(ctx, startNodes, constraints)
+	}
+	spurNode := previousRoute[spurIdx]
+	// The route can't go back to the stations before the spur node, apart from changing lines at the spur station
+	for _, routeNode := range previousRoute[:spurIdx] {
+		constraints.blockedStations[routeNode.Station.Code] = true
+		if routeNode.Station.Name != spurNode.Station.Name {
+			constraints.blockedStationNames[routeNode.Station.Name] = true
+		}
+	}
+	return s.shortestRoute(ctx, []*common.RouteNode{spurNode}, constraints)
+}
+
+// startNodes returns the route nodes of the station codes the search starts from that are open on the network date and not closed by a disruption
+func (s *routeSearch) startNodes(constraints *routeConstraints) ([]*common.RouteNode, error) {
+	var startNodes []*common.RouteNode
+	for _, startCode := range s.startCodes {
+		lineName, stNumber, err := utils.GetStationMetadataFromCode(startCode)
+		if err != nil {
+			return nil, err
+		}
+		station := s.nw.trainLine[lineName][stNumber]
+		if !isStationOpen(station, s.options.networkDate) || constraints.blockedSegments[segmentKey("", station.Code)] || constraints.isStationBlocked(station) {
+			continue // Platforms of lines that haven't opened yet or are avoided can't be used
+		}
+		startNode := &common.RouteNode{Station: station}
+		if isStationClosed(s.activeDisruptions(startNode), station) {
+			continue
+		}
+		startNodes = append(startNodes, startNode)
+	}
+	return startNodes, nil
+}
+
+// shortestRoute finds the cheapest route from any of the start nodes to any of the end station codes using Dijkstra's algorithm
+// It returns the route node of the end station, nil if it can't be reached
+func (s *routeSearch) shortestRoute(ctx context.Context, startNodes []*common.RouteNode, constraints *routeConstraints) (*common.RouteNode, error) {
+	queue := &routeQueue{}
+	bestCosts := map[string]routeCost{}
+	for _, startNode := range startNodes {
+		cost := getRouteCost(startNode, s.options)
+		bestCosts[s.searchStateKey(startNode.Station, startNode.TransferCount)] = cost
+		queue.push(startNode, cost)
+	}
+	settledStates := map[string]bool{}
+	for queue.Len() > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		routeNode := queue.pop()
+		if settledStates[s.searchStateKey(routeNode.Station, routeNode.TransferCount)] {
+			continue // The station was already reached with a lower cost
+		}
+		settledStates[s.searchStateKey(routeNode.Station, routeNode.TransferCount)] = true
+		if s.endCodes[routeNode.Station.Code] {
+			return routeNode, nil
+		}
+		for _, nextStation := range s.adjacentStations(routeNode.Station, s.activeDisruptions(routeNode)) {
+			if constraints.isStationBlocked(nextStation) || constraints.blockedSegments[segmentKey(routeNode.Station.Code, nextStation.Code)] {
+				continue
+			}
+			transferCount := routeNode.TransferCount
+			if s.nw.isTransfer(routeNode.Station, nextStation) {
+				transferCount++
+			}
+			if settledStates[s.searchStateKey(nextStation, transferCount)] || (s.options.maxTransfers >= 0 && transferCount > int64(s.options.maxTransfers)) {
+				continue
+			}
+			if funk.ContainsString(s.startCodes, nextStation.Code) {
+				continue // A route only starts at the source station, it never changes lines to get there
+			}
+			previousNode := s.previousNode(routeNode)
+			if s.nw.isTransfer(routeNode.Station, nextStation) && previousNode != nil && s.nw.isTransfer(previousNode.Station, routeNode.Station) {
+				continue // The line is changed directly to the line required instead of changing twice or walking after a change
+			}
+			stationCount, estimatedTime, isNotOperational, err := s.getSegmentEstimate(routeNode, nextStation)
+			if err != nil {
+				return nil, err
+			}
+			if isNotOperational {
+				continue
+			}
+			nextRouteNode := &common.RouteNode{
+				Station:       nextStation,
+				StationCount:  routeNode.StationCount + stationCount,
+				EstimatedTime: routeNode.EstimatedTime + estimatedTime,
+				TransferCount: transferCount,
+			}
+			if s.backward {
+				nextRouteNode.NextNode = routeNode
+			} else {
+				nextRouteNode.PrevNode = routeNode
+			}
+			cost := getRouteCost(nextRouteNode, s.options)
+			stateKey := s.searchStateKey(nextStation, transferCount)
+			if bestCost, ok := bestCosts[stateKey]; ok && !cost.less(bestCost) {
+				continue
+			}
+			bestCosts[stateKey] = cost
+			queue.push(nextRouteNode, cost)
+		}
+	}
+	return nil, nil
+}
+
+// searchStateKey returns the key of reaching the station in the shortest route search
+// With a limit on the line changes the station reached with different numbers of line changes are different states, as reaching it
+// with a higher cost but fewer line changes may be the only way to reach the end station within the limit
+func (s *routeSearch) searchStateKey(station *common.Station, transferCount int64) string {
+	if s.options.maxTransfers < 0 {
+		return station.Code
+	}
+	return fmt.Sprintf("%s#%d", station.Code, transferCount)
+}
+
+// getSegmentEstimate returns the estimate to travel between the station of the route node and the next station in the order of the search
+// Both searches evaluate the segment at the time it is departed so that travelling forward from the departure time takes the same time.
+// The forward search departs at the time the station of the route node is reached. The backward search has to arrive at the station
+// of the route node by the arrival time minus the time elapsed so far, so the departure depends on the estimate itself and the
+// segment is evaluated again at the departure until the estimate settles
+func (s *routeSearch) getSegmentEstimate(routeNode *common.RouteNode, nextStation *common.Station) (int64, int64, bool, error) {
+	if !s.backward {
+		return getRouteEstimate(s.nw, routeNode.Station.Code, nextStation.Code, s.segmentQueryTime(routeNode))
+	}
+	segmentArrivalTime := s.segmentQueryTime(routeNode)
+	stationCount, estimatedTime, isNotOperational, err := getRouteEstimate(s.nw, nextStation.Code, routeNode.Station.Code, segmentArrivalTime)
+	if err != nil || isNotOperational || segmentArrivalTime.IsZero() {
+		return stationCount, estimatedTime, isNotOperational, err
+	}
+	longestTime := estimatedTime
+	for iteration := 0; iteration < MAX_SEGMENT_ESTIMATE_ITERATIONS; iteration++ {
+		_, departureEstimatedTime, isNotOperational, err := getRouteEstimate(s.nw, nextStation.Code, routeNode.Station.Code, elapsedQueryTime(segmentArrivalTime, -estimatedTime))
+		if err != nil || isNotOperational {
+			return 0, 0, isNotOperational, err
+		}
+		if departureEstimatedTime == estimatedTime {
+			return stationCount, estimatedTime, false, nil
+		}
+		estimatedTime = departureEstimatedTime
+		if estimatedTime > longestTime {
+			longestTime = estimatedTime
+		}
+	}
+	// The estimate alternates at the boundary of the time rules, departing early enough for the longest one still arrives in time
+	return stationCount, longestTime, false, nil
+}
+
+// segmentQueryTime returns the time at which the segments from the route node are evaluated, zero if there's no start or arrival time
+func (s *routeSearch) segmentQueryTime(routeNode *common.RouteNode) time.Time {
+	if s.backward {
+		return elapsedQueryTime(s.options.arriveBy, -routeNode.EstimatedTime)
+	}
+	return elapsedQueryTime(s.options.startTime, routeNode.EstimatedTime)
+}
+
+// activeDisruptions returns the disruptions active at the time the segments from the route node are travelled
+// Without a start or arrival time the disruptions active at any time on the network date are considered
+func (s *routeSearch) activeDisruptions(routeNode *common.RouteNode) []*Disruption {
+	if len(s.options.disruptions) == 0 {
+		return nil
+	}
+	if !s.options.isTimed() {
+		startTime, endTime := s.options.networkDay()
+		return activeDisruptions(s.options.disruptions, startTime, endTime)
+	}
+	queryTime := s.segmentQueryTime(routeNode)
+	return activeDisruptions(s.options.disruptions, queryTime, queryTime.Add(time.Minute))
+}
+
+// previousNode returns the route node that the route node was reached from in the order of the search
+func (s *routeSearch) previousNode(routeNode *common.RouteNode) *common.RouteNode {
+	if s.backward {
+		return routeNode.NextNode
+	}
+	return routeNode.PrevNode
+}
+
+// adjacentStations returns the stations open on the network date that can be travelled to directly from the station
+// i.e. the next and previous stations on the line, the stations of the other lines at the same station and the stations that can be walked to
+// The trains pass through the stations closed by the disruptions without stopping, and the closed segments of the line aren't travelled
+// With the track links the trains run along the track links instead of the next and previous stations on the line
+func (s *routeSearch) adjacentStations(station *common.Station, disruptions []*Disruption) []*common.Station {
+	networkDate := s.options.networkDate
+	var stations []*common.Station
+	if s.nw.hasTrackLinks() {
+		stations = s.trackRunStations(station, disruptions)
+	} else {
+		stations = s.lineStations(station, disruptions)
+	}
+	for _, linkedStation := range station.LinkedStations {
+		// Can't change to a line that hasn't opened yet or is closed at the station
+		if isStationOpen(linkedStation, networkDate) && !isStationClosed(disruptions, linkedStation) {
+			stations = append(stations, linkedStation)
+		}
+	}
+	for _, walkingStation := range s.nw.walkingStations(station) {
+		if isStationOpen(walkingStation, networkDate) && !isStationClosed(disruptions, walkingStation) {
+			stations = append(stations, walkingStation)
+		}
+	}
+	return stations
+}
+
+// lineStations returns the next and previous stations on the line of the station that the trains stop at
+func (s *routeSearch) lineStations(station *common.Station, disruptions []*Disruption) []*common.Station {
+	networkDate := s.options.networkDate
+	var stations []*common.Station
+	for _, towardsNextStation := range []bool{true, false} {
+		nextStation := station.PrevStation
+		if towardsNextStation {
+			nextStation = station.NextStation
+		}
+		nextStation = findOpenStation(nextStation, networkDate, towardsNextStation)
+		for nextStation != nil && isStationClosed(disruptions, nextStation) {
+			if towardsNextStation {
+				nextStation = findOpenStation(nextStation.NextStation, networkDate, true)
+			} else {
+				nextStation = findOpenStation(nextStation.PrevStation, networkDate, false)
+			}
+		}
+		if nextStation != nil && !isSegmentClosed(s.nw, disruptions, station, nextStation) {
+			stations = append(stations, nextStation)
+		}
+	}
+	return stations
+}
+
+// trackRunStations returns the stations that the trains stop at next when running along the track links from the station in the
+// order of the search. The trains pass through the stations that haven't opened yet or are closed by the disruptions
+func (s *routeSearch) trackRunStations(station *common.Station, disruptions []*Disruption) []*common.Station {
+	var stations []*common.Station
+	visitedStations := map[string]bool{station.Code: true}
+	pendingStations := s.nw.trackStations(station, s.backward)
+	for len(pendingStations) > 0 {
+		nextStation := pendingStations[0]
+		pendingStations = pendingStations[1:]
+		if visitedStations[nextStation.Code] {
+			continue
+		}
+		visitedStations[nextStation.Code] = true
+		if !isStationOpen(nextStation, s.options.networkDate) || isStationClosed(disruptions, nextStation) {
+			pendingStations = append(pendingStations, s.nw.trackStations(nextStation, s.backward)...)
+			continue
+		}
+		if !isSegmentClosed(s.nw, disruptions, station, nextStation) {
+			stations = append(stations, nextStation)
+		}
+	}
+	return stations
+}
+
+// isLineChange checks whether travelling between the stations is a change of lines at the same station
+func isLineChange(station, nextStation *common.Station) bool {
+	return station.Name == nextStation.Name
+}
+
+// routeNodeList returns the route nodes in the order of the search from the station the search starts from to the route node
+func (s *routeSearch) routeNodeList(routeNode *common.RouteNode) []*common.RouteNode {
+	var routeNodes []*common.RouteNode
+	for ; routeNode != nil; routeNode = s.previousNode(routeNode) {
+		routeNodes = append(routeNodes, routeNode)
+	}
+	return funk.Reverse(routeNodes).([]*common.RouteNode)
+}
+
+// routeKey returns the key identifying the stations of the route
+func (s *routeSearch) routeKey(routeNode *common.RouteNode) string {
+	var codes []string
+	for _, node := range s.routeNodeList(routeNode) {
+		codes = append(codes, node.Station.Code)
+	}
+	return strings.Join(codes, ",")
+}
+
+// isSameRoute checks whether both the lists of route nodes travel through the same stations
+func isSameRoute(routeNodes, otherRouteNodes []*common.RouteNode) bool {
+	if len(routeNodes) != len(otherRouteNodes) {
+		return false
+	}
+	for idx := range routeNodes {
+		if routeNodes[idx].Station.Code != otherRouteNodes[idx].Station.Code {
+			return false
+		}
+	}
+	return true
+}
+
+// elapsedQueryTime returns the time after the elapsed minutes from the start time, zero if there's no start time
+// The elapsed minutes are negative to go back in time from the arrival time
+func elapsedQueryTime(startTime time.Time, elapsedTimeInMinutes int64) time.Time {
+	if startTime.IsZero() {
+		return startTime
+	}
+	return startTime.Add(time.Duration(elapsedTimeInMinutes) * time.Minute)
+}
+
+// isStationOpen checks whether the station is open on the network date
+func isStationOpen(station *common.Station, networkDate time.Time) bool {
+	return station.OpenedOn.IsZero() || !station.OpenedOn.After(networkDate)
+}
+
+// isAnyStationOpen checks whether any of the station codes are open on the network date
+func isAnyStationOpen(nw *Network, stationCodes []string, networkDate time.Time) bool {
+	for _, stationCode := range stationCodes {
+		station := nw.station(stationCode)
+		if station != nil && isStationOpen(station, networkDate) {
+			return true
+		}
+	}
+	return false
+}
+
+// findOpenStation returns the first station open on the network date starting from the given station in the direction of travel
+// Stations that haven't opened yet are passed through as the trains run through them without stopping
+func findOpenStation(station *common.Station, networkDate time.Time, towardsNextStation bool) *common.Station {
+	for station != nil && !isStationOpen(station, networkDate) {
+		if towardsNextStation {
+			station = station.NextStation
+		} else {
+			station = station.PrevStation
+		}
+	}
+	return station
+}
+
+// getRouteEstimate returns the station count, estimated time and whether the line is not operational to travel between the stations at the query time
+// The estimated time isn't calculated if the query time is zero. A walk between the stations doesn't count as a station and takes the walking time
+// With the track links a run between the stations counts as a station on the line ridden and takes the run time
+func getRouteEstimate(nw *Network, startStationCode, endStationCode string, queryTime time.Time) (int64, int64, bool, error) {
+	if walkingTime, ok := nw.walkingTime(startStationCode, endStationCode); ok {
+		if queryTime.IsZero() {
+			return 0, 0, false, nil
+		}
+		return 0, walkingTime, false, nil
+	}
+	startLineName, _, err := utils.GetStationMetadataFromCode(startStationCode)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	endLineName, _, err := utils.GetStationMetadataFromCode(endStationCode)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	sameLine := startLineName == endLineName
+	runTime, isTrackRun := nw.trackRunTime(startStationCode, endStationCode)
+	if nw.hasTrackLinks() {
+		sameLine = isTrackRun // The trains can run through to another line
+	}
+	if isTrackRun {
+		startLineName = nw.rideLine(startStationCode, endStationCode) // The rules of the line actually ridden apply
+	}
+	var stationCount int64
+	if sameLine {
+		stationCount = 1 // If both stations are on same line count the station
+	}
+	if queryTime.IsZero() {
+		return stationCount, 0, false, nil // Skip processing if query time wasn't provided
+	}
+	lineTimeRules, ok := nw.timeRules[startLineName]
+	if !ok {
+		// Assuming default is always there
+		lineTimeRules, ok = nw.timeRules[DEFAULT_KEY]
+	}
+	if lineTimeRules == nil || !ok {
+		return 0, 0, false, fmt.Errorf("missing train line config")
+	}
+	// Use the applicable rule with the highest priority
+	var eligibleTimeRule *TimeRule
+	for _, timeRule := range lineTimeRules.Rules {
+		isApplicable, err := timeRule.isApplicable(queryTime, nw.holidays)
+		if err != nil {
+			return 0, 0, false, err
+		}
+		if isApplicable && (eligibleTimeRule == nil || timeRule.Priority > eligibleTimeRule.Priority) {
+			eligibleTimeRule = timeRule
+		}
+	}
+	eligibleTrainLineMeta := lineTimeRules.Default
+	if eligibleTimeRule != nil {
+		eligibleTrainLineMeta = &eligibleTimeRule.TrainLineMeta
+	}
+	if eligibleTrainLineMeta == nil {
+		// lookup for default station config in default time
+		defaultLineTimeRules, ok := nw.timeRules[DEFAULT_KEY]
+		if !ok || defaultLineTimeRules == nil || defaultLineTimeRules.Default == nil {
+			return 0, 0, false, fmt.Errorf("missing train line config")
+		}
+		eligibleTrainLineMeta = defaultLineTimeRules.Default
+	}
+	estimedTimeInMinutes, isNotOperational := getEstimatedTimeFromTrainLineMeta(eligibleTrainLineMeta, sameLine)
+	if isTrackRun && !isNotOperational {
+		estimedTimeInMinutes = runTime
+	}
+	return stationCount, estimedTimeInMinutes, isNotOperational, nil
+}
+
+func getEstimatedTimeFromTrainLineMeta(trainLineMeta *TrainLineMeta, sameLine bool) (int64, bool) {
+	if trainLineMeta.IsNotOperational && sameLine {
+		return 0, true
+	}
+	if sameLine {
+		return trainLineMeta.NextStationTimeInMinutes, false
+	}
+	return trainLineMeta.LineChangeTimeInMinutes, false
+}
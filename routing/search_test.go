@@ -0,0 +1,303 @@
+package routing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+)
+
+// newTestNetwork builds the network from the embedded station map with the compiled-in rules
+func newTestNetwork(t *testing.T) *Network {
+	nw, err := NewEmbeddedNetwork(TrainLineTimeExceptionRules)
+	assert.Nil(t, err)
+	return nw
+}
+
+// estimateRoute travels the route from the start time and evaluates each segment at the time it is actually travelled
+// It returns the station count, estimated time and whether any segment is not operational at the time it is travelled
+// The tests use it to check the estimates of the planned routes independently of the search
+func estimateRoute(nw *Network, stationPath []*common.Station, startTime time.Time) (int64, int64, bool, error) {
+	var stationCount, estimatedTime int64
+	for idx := 0; idx+1 < len(stationPath); idx++ {
+		segmentCount, segmentTime, isNotOperational, err := getRouteEstimate(nw, stationPath[idx].Code, stationPath[idx+1].Code, elapsedQueryTime(startTime, estimatedTime))
+		if err != nil {
+			return 0, 0, false, err
+		}
+		if isNotOperational {
+			return 0, 0, true, nil
+		}
+		stationCount += segmentCount
+		estimatedTime += segmentTime
+	}
+	return stationCount, estimatedTime, false, nil
+}
+
+// parseTestTime parses the time in the format of the startTime query param
+func parseTestTime(t *testing.T, value string) time.Time {
+	parsedTime, err := time.Parse("2006-01-02T15:04", value)
+	assert.Nil(t, err)
+	return parsedTime
+}
+
+func TestGetRouteEstimate(t *testing.T) {
+	t.Run("gets the route estimate", func(t *testing.T) {
+		testCases := []struct {
+			sourceStation      string
+			destinationStation string
+			queryTime          string
+			stationCount       int64
+			estimatedTime      int64
+			isNotOperational   bool
+			err                error
+		}{
+			{
+				// peak hours
+				sourceStation:      "NS1",
+				destinationStation: "NS2",
+				queryTime:          "2019-01-31T08:00",
+				stationCount:       1,
+				estimatedTime:      12,
+				isNotOperational:   false,
+				err:                nil,
+			},
+			{
+				// peak hours
+				sourceStation:      "NS1",
+				destinationStation: "DT2",
+				queryTime:          "2019-01-31T08:00",
+				stationCount:       0,
+				estimatedTime:      15,
+				isNotOperational:   false,
+				err:                nil,
+			},
+			{
+				// peak hours
+				sourceStation:      "CC1",
+				destinationStation: "DT2",
+				queryTime:          "2019-01-31T08:00",
+				stationCount:       0,
+				estimatedTime:      15,
+				isNotOperational:   false,
+				err:                nil,
+			},
+			{
+				// peak hours
+				sourceStation:      "DT1",
+				destinationStation: "DT2",
+				queryTime:          "2019-01-31T08:00",
+				stationCount:       1,
+				estimatedTime:      10,
+				isNotOperational:   false,
+				err:                nil,
+			},
+			{
+				// night hours
+				sourceStation:      "DT1",
+				destinationStation: "NS2",
+				queryTime:          "2019-01-31T01:00",
+				stationCount:       0,
+				estimatedTime:      10,
+				isNotOperational:   false,
+				err:                nil,
+			},
+			{
+				// night hours
+				sourceStation:      "DT1",
+				destinationStation: "DT2",
+				queryTime:          "2019-01-31T01:00",
+				stationCount:       1,
+				estimatedTime:      0,
+				isNotOperational:   true,
+			},
+			{
+				// night hours
+				sourceStation:      "CC1",
+				destinationStation: "DT2",
+				queryTime:          "2019-01-31T01:00",
+				stationCount:       0,
+				estimatedTime:      10,
+				isNotOperational:   false,
+				err:                nil,
+			},
+			{
+				// night hours
+				sourceStation:      "TE1",
+				destinationStation: "TE2",
+				queryTime:          "2019-01-31T01:00",
+				stationCount:       1,
+				estimatedTime:      8,
+				isNotOperational:   false,
+				err:                nil,
+			},
+			{
+				// non-peak hours
+				sourceStation:      "DT1",
+				destinationStation: "NS2",
+				queryTime:          "2019-01-31T17:00",
+				stationCount:       0,
+				estimatedTime:      10,
+				isNotOperational:   false,
+				err:                nil,
+			},
+			{
+				// non-peak hours
+				sourceStation:      "DT1",
+				destinationStation: "DT2",
+				queryTime:          "2019-01-31T17:00",
+				stationCount:       1,
+				estimatedTime:      8,
+				isNotOperational:   false,
+			},
+			{
+				// non-peak hours
+				sourceStation:      "CC1",
+				destinationStation: "CG2",
+				queryTime:          "2019-01-31T17:00",
+				stationCount:       0,
+				estimatedTime:      10,
+				isNotOperational:   false,
+				err:                nil,
+			},
+			// TODO: Add a test case for error check
+		}
+		for _, testCase := range testCases {
+			stationCount, estimatedTime, isNotOperational, err := getRouteEstimate(newTestNetwork(t), testCase.sourceStation, testCase.destinationStation, parseTestTime(t, testCase.queryTime))
+			assert.Equal(t, testCase.err, err)
+			assert.Equal(t, testCase.stationCount, stationCount)
+			assert.Equal(t, testCase.estimatedTime, estimatedTime)
+			assert.Equal(t, testCase.isNotOperational, isNotOperational)
+		}
+	})
+}
+
+func TestFetchRoutes(t *testing.T) {
+	t.Run("fetches the routes with estimated time", func(t *testing.T) {
+		options := newPlanOptions([]PlanOption{WithStartTime(parseTestTime(t, "2022-01-31T17:00"))})
+		nw := newTestNetwork(t)
+		routes, err := fetchRoutes(context.Background(), nw, nw.stationNameCodeMap["Marsiling"], nw.stationNameCodeMap["Yio Chu Kang"], options)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(routes))
+		stationPath := generateStationList(routes[0])
+		assert.Equal(t, 8, len(stationPath))
+		assert.Equal(t, "NS8", stationPath[0].Code)
+		assert.Equal(t, "NS9", stationPath[1].Code)
+		assert.Equal(t, "NS15", stationPath[7].Code)
+		assert.Equal(t, int64(6*10+12), routes[0].EstimatedTime) // The last segment is travelled at 6PM in the peak hours
+	})
+
+	t.Run("orders the loopless alternatives by their cost", func(t *testing.T) {
+		options := newPlanOptions([]PlanOption{WithStartTime(parseTestTime(t, "2022-01-31T17:00")), WithMaxRoutes(MAX_ROUTES)})
+		nw := newTestNetwork(t)
+		routes, err := fetchRoutes(context.Background(), nw, nw.stationNameCodeMap["Boon Lay"], nw.stationNameCodeMap["Little India"], options)
+		assert.Nil(t, err)
+		assert.Equal(t, MAX_ROUTES, len(routes))
+		search := &routeSearch{}
+		routeKeys := map[string]bool{}
+		for idx, route := range routes {
+			assert.False(t, routeKeys[search.routeKey(route)])
+			routeKeys[search.routeKey(route)] = true
+			if idx > 0 {
+				assert.True(t, routes[idx-1].EstimatedTime <= route.EstimatedTime)
+			}
+			stationCodes := map[string]bool{}
+			for _, station := range generateStationList(route) {
+				assert.False(t, stationCodes[station.Code]) // No station is visited twice
+				stationCodes[station.Code] = true
+			}
+			stationCount, estimatedTime, _, err := estimateRoute(nw, generateStationList(route), options.startTime)
+			assert.Nil(t, err)
+			assert.Equal(t, stationCount, route.StationCount)
+			assert.Equal(t, estimatedTime, route.EstimatedTime)
+		}
+	})
+
+	t.Run("finds the route with the fewest stations without a start time", func(t *testing.T) {
+		options := newPlanOptions([]PlanOption{WithNetworkDate(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)), WithMaxRoutes(1)})
+		nw := newTestNetwork(t)
+		routes, err := fetchRoutes(context.Background(), nw, nw.stationNameCodeMap["Marsiling"], nw.stationNameCodeMap["Yio Chu Kang"], options)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(routes))
+		assert.Equal(t, int64(7), routes[0].StationCount)
+		assert.Equal(t, int64(0), routes[0].EstimatedTime)
+	})
+
+	t.Run("skips the stations that haven't opened on the start date", func(t *testing.T) {
+		options := newPlanOptions([]PlanOption{WithStartTime(parseTestTime(t, "2019-01-31T17:00"))})
+		nw := newTestNetwork(t)
+		routes, err := fetchRoutes(context.Background(), nw, nw.stationNameCodeMap["Marsiling"], nw.stationNameCodeMap["Yio Chu Kang"], options)
+		assert.Nil(t, err)
+		assert.NotEmpty(t, routes)
+		for _, route := range routes {
+			for _, station := range generateStationList(route) {
+				assert.NotEqual(t, "TE", station.Code[:2]) // Thomson-East Coast line opened on 31 December 2019
+				assert.NotEqual(t, "NS12", station.Code)   // Canberra opened in December 2019
+			}
+		}
+		stationPath := generateStationList(routes[0])
+		assert.Equal(t, 7, len(stationPath))
+		assert.Equal(t, "NS13", stationPath[4].Code)
+	})
+
+	t.Run("searches backwards from the arrival time", func(t *testing.T) {
+		options := newPlanOptions([]PlanOption{WithArriveBy(parseTestTime(t, "2022-01-31T09:10")), WithMaxRoutes(1)})
+		nw := newTestNetwork(t)
+		routes, err := fetchRoutes(context.Background(), nw, []string{"NS1"}, []string{"NS4"}, options)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(routes))
+		// The route node of the source station is returned and the segments departing before 9AM are in the peak hours
+		assert.Equal(t, "NS1", routes[0].Station.Code)
+		assert.Equal(t, int64(3), routes[0].StationCount)
+		assert.Equal(t, int64(12+12+10), routes[0].EstimatedTime)
+		stationPath := generateStationList(routes[0])
+		assert.Equal(t, []string{"NS1", "NS2", "NS3", "NS4"}, []string{stationPath[0].Code, stationPath[1].Code, stationPath[2].Code, stationPath[3].Code})
+		// Travelling forward from the departure time arrives by the arrival time
+		_, estimatedTime, _, err := estimateRoute(nw, stationPath, parseTestTime(t, "2022-01-31T08:36"))
+		assert.Nil(t, err)
+		assert.Equal(t, routes[0].EstimatedTime, estimatedTime)
+	})
+
+	t.Run("returns no routes on a non-operational path", func(t *testing.T) {
+		options := newPlanOptions([]PlanOption{WithStartTime(parseTestTime(t, "2019-01-31T01:00"))})
+		nw := newTestNetwork(t)
+		routes, err := fetchRoutes(context.Background(), nw, nw.stationNameCodeMap["Bencoolen"], nw.stationNameCodeMap["Ubi"], options)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(routes))
+	})
+}
+
+func TestEstimateRoute(t *testing.T) {
+	nw := newTestNetwork(t)
+	stationPath := []*common.Station{nw.station("NS1"), nw.station("NS2"), nw.station("NS3"), nw.station("NS4")}
+
+	t.Run("advances the clock along the journey", func(t *testing.T) {
+		// The first segment is travelled in the peak hours and the rest after the peak hours
+		stationCount, estimatedTime, isNotOperational, err := estimateRoute(nw, stationPath, parseTestTime(t, "2022-01-31T08:50"))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), stationCount)
+		assert.Equal(t, int64(12+10+10), estimatedTime)
+		assert.False(t, isNotOperational)
+	})
+
+	t.Run("cuts off the journey when the line closes on the way", func(t *testing.T) {
+		stationPath := []*common.Station{nw.station("DT21"), nw.station("DT22"), nw.station("DT23")}
+		_, _, isNotOperational, err := estimateRoute(nw, stationPath, parseTestTime(t, "2022-01-31T21:55"))
+		assert.Nil(t, err)
+		assert.True(t, isNotOperational)
+
+		options := newPlanOptions([]PlanOption{WithStartTime(parseTestTime(t, "2022-01-31T21:55"))})
+		routes, err := fetchRoutes(context.Background(), nw, nw.stationNameCodeMap["Bencoolen"], nw.stationNameCodeMap["Expo"], options)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(routes))
+	})
+
+	t.Run("counts the stations without a start time", func(t *testing.T) {
+		stationCount, estimatedTime, isNotOperational, err := estimateRoute(nw, stationPath, time.Time{})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), stationCount)
+		assert.Equal(t, int64(0), estimatedTime)
+		assert.False(t, isNotOperational)
+	})
+}
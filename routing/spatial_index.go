@@ -0,0 +1,156 @@
+package routing
+
+import (
+	"math"
+	"sort"
+
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+)
+
+const (
+	SPATIAL_INDEX_CELL_SIZE_IN_DEGREES = 0.01   // The size of the grid cells of the spatial index which is about 1.1 km
+	EARTH_RADIUS_IN_KM                 = 6371.0 // Used to calculate the distance between two coordinates
+	KM_PER_DEGREE                      = 111.19 // The length of a degree of latitude, or of longitude at the equator
+)
+
+// spatialIndex is a grid over the station coordinates used to find the stations nearest to a location
+type spatialIndex struct {
+	cells   map[gridCell][]string // Key is the grid cell and value is the list of station codes in it
+	minCell gridCell              // The lowest row and column of the cells that have stations
+	maxCell gridCell              // The highest row and column of the cells that have stations
+}
+
+// gridCell is a cell of the spatial index, the row is along the latitude and the column is along the longitude
+type gridCell struct {
+	row int64
+	col int64
+}
+
+// nearbyStation is a station near a location along with the distance to its nearest platform
+type nearbyStation struct {
+	stationName  string
+	distanceInKm float64
+}
+
+func toGridCell(coordinates *common.Coordinates) gridCell {
+	return gridCell{
+		row: int64(math.Floor(coordinates.Latitude / SPATIAL_INDEX_CELL_SIZE_IN_DEGREES)),
+		col: int64(math.Floor(coordinates.Longitude / SPATIAL_INDEX_CELL_SIZE_IN_DEGREES)),
+	}
+}
+
+// newSpatialIndex builds the index over the station coordinates
+func newSpatialIndex(coordinates StationCoordinates) *spatialIndex {
+	index := &spatialIndex{cells: map[gridCell][]string{}}
+	for stationCode, stationCoordinates := range coordinates {
+		cell := toGridCell(stationCoordinates)
+		if len(index.cells) == 0 {
+			index.minCell, index.maxCell = cell, cell
+		}
+		index.cells[cell] = append(index.cells[cell], stationCode)
+		index.minCell = gridCell{row: minInt64(index.minCell.row, cell.row), col: minInt64(index.minCell.col, cell.col)}
+		index.maxCell = gridCell{row: maxInt64(index.maxCell.row, cell.row), col: maxInt64(index.maxCell.col, cell.col)}
+	}
+	return index
+}
+
+// nearest returns up to the limit stations nearest to the location within the max distance ordered by the distance and then the name
+// The rings of cells around the cell of the location are searched outwards until none of the cells left can have a nearer station
+func (index *spatialIndex) nearest(nw *Network, location *common.Coordinates, limit int, maxDistanceInKm float64) []*nearbyStation {
+	distances := map[string]float64{} // Key is the station name and value is the distance to its nearest platform
+	center := toGridCell(location)
+	// The width of a cell in km is the narrowest along the longitude, away from the equator
+	cellWidthInKm := SPATIAL_INDEX_CELL_SIZE_IN_DEGREES * KM_PER_DEGREE * math.Cos(location.Latitude*math.Pi/180)
+	lastRing := maxInt64(
+		maxInt64(absInt64(center.row-index.minCell.row), absInt64(center.row-index.maxCell.row)),
+		maxInt64(absInt64(center.col-index.minCell.col), absInt64(center.col-index.maxCell.col)),
+	)
+	for ring := int64(0); len(index.cells) > 0 && ring <= lastRing; ring++ {
+		for _, cell := range index.ringCells(center, ring) {
+			for _, stationCode := range index.cells[cell] {
+				distance := haversineDistance(location, nw.stationCoordinates(stationCode))
+				stationName := nw.stationCodeNameMap[stationCode]
+				if existingDistance, ok := distances[stationName]; !ok || distance < existingDistance {
+					distances[stationName] = distance
+				}
+			}
+		}
+		// The stations in the rings further out are at least this far from the location
+		minDistanceOutside := float64(ring) * cellWidthInKm
+		if minDistanceOutside > maxDistanceInKm || countWithin(distances, minDistanceOutside) >= limit {
+			break
+		}
+	}
+	var stations []*nearbyStation
+	for stationName, distance := range distances {
+		if distance <= maxDistanceInKm {
+			stations = append(stations, &nearbyStation{stationName: stationName, distanceInKm: distance})
+		}
+	}
+	sort.Slice(stations, func(i, j int) bool {
+		if stations[i].distanceInKm != stations[j].distanceInKm {
+			return stations[i].distanceInKm < stations[j].distanceInKm
+		}
+		return stations[i].stationName < stations[j].stationName
+	})
+	if len(stations) > limit {
+		stations = stations[:limit]
+	}
+	return stations
+}
+
+// ringCells returns the cells with stations at the ring around the center cell i.e. the cells whose row or column is the ring away from it
+func (index *spatialIndex) ringCells(center gridCell, ring int64) []gridCell {
+	var cells []gridCell
+	for row := maxInt64(center.row-ring, index.minCell.row); row <= minInt64(center.row+ring, index.maxCell.row); row++ {
+		if absInt64(row-center.row) == ring {
+			for col := maxInt64(center.col-ring, index.minCell.col); col <= minInt64(center.col+ring, index.maxCell.col); col++ {
+				cells = append(cells, gridCell{row: row, col: col})
+			}
+			continue
+		}
+		cells = append(cells, gridCell{row: row, col: center.col - ring}, gridCell{row: row, col: center.col + ring})
+	}
+	return cells
+}
+
+// countWithin returns the number of the distances that are within the max distance
+func countWithin(distances map[string]float64, maxDistanceInKm float64) int {
+	count := 0
+	for _, distance := range distances {
+		if distance <= maxDistanceInKm {
+			count++
+		}
+	}
+	return count
+}
+
+// haversineDistance returns the great circle distance in km between the coordinates
+func haversineDistance(from, to *common.Coordinates) float64 {
+	fromLatitude, toLatitude := from.Latitude*math.Pi/180, to.Latitude*math.Pi/180
+	latitudeDelta := toLatitude - fromLatitude
+	longitudeDelta := (to.Longitude - from.Longitude) * math.Pi / 180
+	a := math.Sin(latitudeDelta/2)*math.Sin(latitudeDelta/2) + math.Cos(fromLatitude)*math.Cos(toLatitude)*math.Sin(longitudeDelta/2)*math.Sin(longitudeDelta/2)
+	return 2 * EARTH_RADIUS_IN_KM * math.Asin(math.Sqrt(a))
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func absInt64(value int64) int64 {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
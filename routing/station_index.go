@@ -0,0 +1,182 @@
+package routing
+
+import (
+	"sort"
+	"strings"
+
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+)
+
+const (
+	MAX_SUGGESTIONS = 3 // This is the number of station names suggested when a station name is not recognised
+)
+
+// The ranks of the station name matches, lower rank is a better match
+const (
+	EXACT_MATCH_RANK       = iota // The normalised query is the normalised station name
+	NAME_PREFIX_MATCH_RANK        // The normalised query is a prefix of the normalised station name
+	WORD_PREFIX_MATCH_RANK        // The normalised query is a prefix of a word in the station name
+	EDIT_DISTANCE_RANK            // The query is within the edit distance of the station name, the distance is added to the rank
+)
+
+// stationIndex is used to search the station names for a query that may not be an exact match
+type stationIndex struct {
+	prefixKeys []*stationIndexKey // Sorted by key to find the keys with a prefix using binary search
+	names      map[string]string  // Key is the normalised station name and value is the station name
+}
+
+// stationIndexKey is a key of the prefix index
+// Each station has a key for its normalised name and for the normalised name starting from each of its words
+type stationIndexKey struct {
+	key         string
+	stationName string
+	isFullName  bool
+}
+
+// stationMatch is a station name matching a query with the rank of the match
+type stationMatch struct {
+	stationName string
+	rank        int
+}
+
+// normaliseStationName lower cases the name and collapses the whitespaces so that "HarbourFront " matches "harbourfront"
+func normaliseStationName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// newStationIndex builds the index over the station names
+func newStationIndex(stationNames []string) *stationIndex {
+	index := &stationIndex{names: map[string]string{}}
+	for _, stationName := range stationNames {
+		normalisedName := normaliseStationName(stationName)
+		index.names[normalisedName] = stationName
+		words := strings.Split(normalisedName, " ")
+		for idx := range words {
+			index.prefixKeys = append(index.prefixKeys, &stationIndexKey{
+				key:         strings.Join(words[idx:], " "),
+				stationName: stationName,
+				isFullName:  idx == 0,
+			})
+		}
+	}
+	sort.Slice(index.prefixKeys, func(i, j int) bool {
+		return index.prefixKeys[i].key < index.prefixKeys[j].key
+	})
+	return index
+}
+
+// search returns the station names matching the query ordered by the rank of the match and then the name
+func (index *stationIndex) search(query string, limit int) []string {
+	query = normaliseStationName(query)
+	if query == "" || limit <= 0 {
+		return []string{}
+	}
+	ranks := map[string]int{}
+	addMatch := func(stationName string, rank int) {
+		if existingRank, ok := ranks[stationName]; !ok || rank < existingRank {
+			ranks[stationName] = rank
+		}
+	}
+
+	// prefix matches
+	start := sort.Search(len(index.prefixKeys), func(i int) bool {
+		return index.prefixKeys[i].key >= query
+	})
+	for _, prefixKey := range index.prefixKeys[start:] {
+		if !strings.HasPrefix(prefixKey.key, query) {
+			break
+		}
+		switch {
+		case prefixKey.isFullName && prefixKey.key == query:
+			addMatch(prefixKey.stationName, EXACT_MATCH_RANK)
+		case prefixKey.isFullName:
+			addMatch(prefixKey.stationName, NAME_PREFIX_MATCH_RANK)
+		default:
+			addMatch(prefixKey.stationName, WORD_PREFIX_MATCH_RANK)
+		}
+	}
+
+	// edit distance matches, the query is also compared with the start of the name to match the typos while typing
+	maxDistance := len([]rune(query)) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+	for normalisedName, stationName := range index.names {
+		distance := editDistance(query, normalisedName)
+		if nameRunes := []rune(normalisedName); len(nameRunes) > len([]rune(query)) {
+			prefixDistance := editDistance(query, string(nameRunes[:len([]rune(query))])) + 1 // Penalise the partial match over the full match
+			if prefixDistance < distance {
+				distance = prefixDistance
+			}
+		}
+		if distance <= maxDistance {
+			addMatch(stationName, EDIT_DISTANCE_RANK+distance)
+		}
+	}
+
+	var matches []*stationMatch
+	for stationName, rank := range ranks {
+		matches = append(matches, &stationMatch{stationName: stationName, rank: rank})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].rank != matches[j].rank {
+			return matches[i].rank < matches[j].rank
+		}
+		return matches[i].stationName < matches[j].stationName
+	})
+	stationNames := []string{}
+	for _, match := range matches {
+		if len(stationNames) == limit {
+			break
+		}
+		stationNames = append(stationNames, match.stationName)
+	}
+	return stationNames
+}
+
+// editDistance returns the Levenshtein distance between the strings
+func editDistance(a, b string) int {
+	aRunes, bRunes := []rune(a), []rune(b)
+	prevRow := make([]int, len(bRunes)+1)
+	for j := range prevRow {
+		prevRow[j] = j
+	}
+	for i := 1; i <= len(aRunes); i++ {
+		row := make([]int, len(bRunes)+1)
+		row[0] = i
+		for j := 1; j <= len(bRunes); j++ {
+			substitutionCost := 1
+			if aRunes[i-1] == bRunes[j-1] {
+				substitutionCost = 0
+			}
+			row[j] = minInt(prevRow[j]+1, row[j-1]+1, prevRow[j-1]+substitutionCost)
+		}
+		prevRow = row
+	}
+	return prevRow[len(bRunes)]
+}
+
+func minInt(values ...int) int {
+	min := values[0]
+	for _, value := range values[1:] {
+		if value < min {
+			min = value
+		}
+	}
+	return min
+}
+
+// SearchStations returns the details of the stations matching the query ordered by the closeness of the match
+// The query can be a prefix of the station name or of any of its words and can have typos
+func (nw *Network) SearchStations(query string, limit int) []*common.StationInfo {
+	stations := []*common.StationInfo{}
+	for _, stationName := range nw.stationIndex.search(query, limit) {
+		stations = append(stations, nw.stationInfo(stationName))
+	}
+	return stations
+}
+
+// suggestStationNames returns the station names that the query may have meant
+func (nw *Network) suggestStationNames(query string) []string {
+	return nw.stationIndex.search(query, MAX_SUGGESTIONS)
+}
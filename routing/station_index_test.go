@@ -0,0 +1,51 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchStations(t *testing.T) {
+	nw := newTestNetwork(t)
+
+	t.Run("ranks the stations matching the query", func(t *testing.T) {
+		testCases := []struct {
+			query        string
+			stationNames []string
+		}{
+			{query: "harbourfront", stationNames: []string{"HarbourFront"}},
+			{query: "Changi Airport ", stationNames: []string{"Changi Airport"}},
+			{query: "Dhoby Gaut", stationNames: []string{"Dhoby Ghaut"}},
+			{query: "orch", stationNames: []string{"Orchard", "Orchard Boulevard"}},
+			{query: "marina", stationNames: []string{"Marina Bay", "Marina South", "Marina South Pier"}},
+			{query: "bay", stationNames: []string{"Bayfront", "Gardens by the Bay", "Marina Bay"}},
+			{query: "xyzzy", stationNames: []string{}},
+		}
+		for _, testCase := range testCases {
+			var stationNames []string
+			for _, station := range nw.SearchStations(testCase.query, 3) {
+				stationNames = append(stationNames, station.Name)
+			}
+			if len(testCase.stationNames) == 0 {
+				assert.Empty(t, stationNames, testCase.query)
+				continue
+			}
+			assert.Equal(t, testCase.stationNames, stationNames, testCase.query)
+		}
+	})
+
+	t.Run("returns the details of the matching stations", func(t *testing.T) {
+		stations := nw.SearchStations("jurong e", 1)
+		assert.Equal(t, 1, len(stations))
+		assert.Equal(t, "Jurong East", stations[0].Name)
+		assert.Equal(t, []string{"NS1", "EW24"}, stations[0].Codes)
+	})
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("bishan", "bishan"))
+	assert.Equal(t, 1, editDistance("dhoby gaut", "dhoby ghaut"))
+	assert.Equal(t, 2, editDistance("tampnies", "tampines"))
+	assert.Equal(t, 3, editDistance("", "abc"))
+}
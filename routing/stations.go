@@ -0,0 +1,81 @@
+package routing
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/thoas/go-funk"
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+	"gitlab.myteksi.net/goscripts/zendesk/utils"
+)
+
+// station returns the station for the station code, nil if the code isn't on the network
+func (nw *Network) station(stationCode string) *common.Station {
+	lineCode, stNumber, err := utils.GetStationMetadataFromCode(stationCode)
+	if err != nil {
+		return nil
+	}
+	return nw.trainLine[lineCode][stNumber]
+}
+
+// resolveStationCodes returns the station codes for the station name or the station code
+// A station name resolves to the codes of all the lines serving the station while a station code only resolves to itself
+func (nw *Network) resolveStationCodes(station string) ([]string, bool) {
+	if stationCodes, ok := nw.stationNameCodeMap[station]; ok {
+		return stationCodes, true
+	}
+	stationCode := strings.ToUpper(strings.TrimSpace(station))
+	if _, ok := nw.stationCodeNameMap[stationCode]; ok {
+		return []string{stationCode}, true
+	}
+	return nil, false
+}
+
+// HasLine checks whether the train line is on the network
+func (nw *Network) HasLine(lineCode string) bool {
+	_, ok := nw.trainLine[lineCode]
+	return ok
+}
+
+// Stations returns the details of the stations ordered by name
+// If the line code is provided only the stations on the train line are returned
+func (nw *Network) Stations(lineCode string) []*common.StationInfo {
+	stations := []*common.StationInfo{}
+	for stationName := range nw.stationNameCodeMap {
+		stationInfo := nw.stationInfo(stationName)
+		if lineCode != "" && !funk.ContainsString(stationInfo.Lines, lineCode) {
+			continue
+		}
+		stations = append(stations, stationInfo)
+	}
+	sort.Slice(stations, func(i, j int) bool {
+		return stations[i].Name < stations[j].Name
+	})
+	return stations
+}
+
+// stationInfo returns the details of the station across all the train lines serving it
+func (nw *Network) stationInfo(stationName string) *common.StationInfo {
+	stationCodes := nw.stationNameCodeMap[stationName]
+	stationInfo := &common.StationInfo{Name: stationName, Codes: append([]string{}, stationCodes...)}
+	var openingStation *common.Station
+	for _, stationCode := range stationCodes {
+		station := nw.station(stationCode)
+		if station == nil {
+			continue
+		}
+		stationLineCode, _, _ := utils.GetStationMetadataFromCode(stationCode)
+		stationInfo.Lines = append(stationInfo.Lines, stationLineCode)
+		if openingStation == nil || station.OpenedOn.Before(openingStation.OpenedOn) {
+			openingStation = station
+		}
+		if stationInfo.Coordinates == nil {
+			stationInfo.Coordinates = nw.stationCoordinates(stationCode)
+		}
+	}
+	if openingStation != nil {
+		stationInfo.OpeningDate = openingStation.OpeningDate
+	}
+	stationInfo.Interchange = len(stationInfo.Lines) > 1
+	return stationInfo
+}
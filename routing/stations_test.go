@@ -0,0 +1,38 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+)
+
+func TestStations(t *testing.T) {
+	nw := newTestNetwork(t)
+
+	t.Run("returns all the stations ordered by name", func(t *testing.T) {
+		stations := nw.Stations("")
+		assert.Equal(t, len(nw.stationNameCodeMap), len(stations))
+		assert.Equal(t, "Admiralty", stations[0].Name)
+		for _, station := range stations {
+			if station.Name == "Jurong East" {
+				assert.Equal(t, &common.StationInfo{
+					Name:        "Jurong East",
+					Codes:       []string{"NS1", "EW24"},
+					Lines:       []string{"NS", "EW"},
+					OpeningDate: "5 November 1988",
+					Interchange: true,
+				}, station)
+			}
+		}
+	})
+
+	t.Run("filters the stations on the line", func(t *testing.T) {
+		stations := nw.Stations("CG")
+		assert.Equal(t, 3, len(stations))
+		assert.Equal(t, "Changi Airport", stations[0].Name)
+		assert.False(t, stations[0].Interchange)
+		assert.Equal(t, "Tanah Merah", stations[2].Name)
+		assert.True(t, stations[2].Interchange)
+	})
+}
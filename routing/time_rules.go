@@ -0,0 +1,107 @@
+package routing
+
+import (
+	"time"
+
+	"github.com/thoas/go-funk"
+)
+
+// ruleDay is a kind of day on which the time rules are evaluated
+type ruleDay struct {
+	weekday time.Weekday
+	holiday bool
+	date    string // The date in DATE_FORMAT, empty if the day isn't a specific date
+}
+
+// String returns the time range of the rule e.g. "10:00PM - 6:00AM"
+func (r *TimeRule) String() string {
+	return r.Start + " - " + r.End
+}
+
+// parseMinutes returns the start and end of the time range as the minutes since midnight
+func (r *TimeRule) parseMinutes() (int, int, error) {
+	start, err := parseMinuteOfDay(r.Start)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := parseMinuteOfDay(r.End)
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// isApplicable checks whether the rule applies at the query time in the timezone of the query time i.e. the network timezone
+// The part of a time range after midnight applies if the rule applies on the previous day on which the time range started
+func (r *TimeRule) isApplicable(queryTime time.Time, holidays HolidayCalendar) (bool, error) {
+	start, end, err := r.parseMinutes()
+	if err != nil {
+		return false, err
+	}
+	minute := queryTime.Hour()*60 + queryTime.Minute()
+	if start < end {
+		return minute >= start && minute < end && r.isApplicableOn(newRuleDay(queryTime, holidays)), nil
+	}
+	if minute >= start {
+		return r.isApplicableOn(newRuleDay(queryTime, holidays)), nil
+	}
+	if minute < end {
+		return r.isApplicableOn(newRuleDay(queryTime.AddDate(0, 0, -1), holidays)), nil
+	}
+	return false, nil
+}
+
+// activeMinutes returns the ranges of minutes since midnight of the day during which the rule applies
+func (r *TimeRule) activeMinutes(previousDay, day ruleDay) [][2]int {
+	start, end, err := r.parseMinutes()
+	if err != nil {
+		return nil
+	}
+	var activeMinutes [][2]int
+	if start < end {
+		if r.isApplicableOn(day) {
+			activeMinutes = append(activeMinutes, [2]int{start, end})
+		}
+		return activeMinutes
+	}
+	if r.isApplicableOn(day) {
+		activeMinutes = append(activeMinutes, [2]int{start, MINUTES_PER_DAY})
+	}
+	if r.isApplicableOn(previousDay) {
+		activeMinutes = append(activeMinutes, [2]int{0, end})
+	}
+	return activeMinutes
+}
+
+// isApplicableOn checks whether the train line meta applies on the day
+// On a holiday it applies if it is for holidays, else the days of the week are considered as if it is a Sunday
+// so that the weekday rules like the peak hours don't apply
+func (m *TrainLineMeta) isApplicableOn(day ruleDay) bool {
+	if day.date != "" && funk.ContainsString(m.DayTypes, day.date) {
+		return true
+	}
+	weekday := day.weekday
+	if day.holiday {
+		if funk.ContainsString(m.DayTypes, HOLIDAY_DAY_TYPE) {
+			return true
+		}
+		weekday = HOLIDAY_WEEKDAY
+	}
+	if funk.ContainsString(m.DayTypes, WEEKEND_DAY_TYPE) && (weekday == time.Saturday || weekday == time.Sunday) {
+		return true
+	}
+	return funk.ContainsString(m.DaysOfWeek, weekday.String())
+}
+
+func newRuleDay(date time.Time, holidays HolidayCalendar) ruleDay {
+	return ruleDay{weekday: date.Weekday(), holiday: holidays.IsHoliday(date), date: date.Format(DATE_FORMAT)}
+}
+
+// parses the time of the day in time.Kitchen format e.g. "6:00AM" into the minutes since midnight
+func parseMinuteOfDay(value string) (int, error) {
+	clockTime, err := time.Parse(time.Kitchen, value)
+	if err != nil {
+		return 0, err
+	}
+	return clockTime.Hour()*60 + clockTime.Minute(), nil
+}
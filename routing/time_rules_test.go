@@ -0,0 +1,50 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeRuleIsApplicable(t *testing.T) {
+	// The night rule starts on Mondays and wraps past midnight into Tuesday
+	timeRule := &TimeRule{Start: "11:00PM", End: "1:00AM", TrainLineMeta: TrainLineMeta{DaysOfWeek: []string{"Monday"}}}
+	testCases := []struct {
+		queryTime    string
+		isApplicable bool
+	}{
+		{queryTime: "2022-01-31T22:59", isApplicable: false},
+		{queryTime: "2022-01-31T23:00", isApplicable: true},
+		{queryTime: "2022-02-01T00:30", isApplicable: true},
+		{queryTime: "2022-02-01T01:00", isApplicable: false}, // The end is excluded
+		{queryTime: "2022-02-01T23:30", isApplicable: false}, // Tuesday
+		{queryTime: "2022-01-31T00:30", isApplicable: false}, // After midnight of Sunday
+	}
+	for _, testCase := range testCases {
+		isApplicable, err := timeRule.isApplicable(parseTestTime(t, testCase.queryTime), nil)
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.isApplicable, isApplicable, testCase.queryTime)
+	}
+}
+
+func TestTimeRulePriority(t *testing.T) {
+	rules := TimeExceptionRule{
+		"NS": {Rules: []*TimeRule{
+			{Start: "6:00AM", End: "9:00AM", TrainLineMeta: TrainLineMeta{NextStationTimeInMinutes: 12, LineChangeTimeInMinutes: 15, DaysOfWeek: workingDays}},
+			{Start: "12:00AM", End: "12:00AM", Priority: 1, TrainLineMeta: TrainLineMeta{IsNotOperational: true, DayTypes: []string{"2022-02-01"}}},
+		}},
+		DEFAULT_KEY: {Default: &TrainLineMeta{NextStationTimeInMinutes: 10, LineChangeTimeInMinutes: 10}},
+	}
+	nw, err := NewEmbeddedNetwork(rules)
+	assert.Nil(t, err)
+
+	_, estimatedTime, isNotOperational, err := getRouteEstimate(nw, "NS1", "NS2", parseTestTime(t, "2022-01-31T08:00"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(12), estimatedTime)
+	assert.False(t, isNotOperational)
+
+	// The closure for the whole day has a higher priority than the peak hours
+	_, _, isNotOperational, err = getRouteEstimate(nw, "NS1", "NS2", parseTestTime(t, "2022-02-01T08:00"))
+	assert.Nil(t, err)
+	assert.True(t, isNotOperational)
+}
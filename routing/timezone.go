@@ -0,0 +1,35 @@
+package routing
+
+import (
+	"fmt"
+	"os"
+	"time"
+	_ "time/tzdata" // The timezone database is embedded so that the network timezone can be loaded on any host
+)
+
+// LoadNetworkLocation loads the timezone in which the time rules of the network are evaluated e.g. "Asia/Singapore"
+func LoadNetworkLocation(name string) (*time.Location, error) {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid network timezone %s: %v", name, err)
+	}
+	return location, nil
+}
+
+// LoadNetworkLocationFromEnv loads the network timezone from the NETWORK_TIMEZONE env variable, defaulting to DEFAULT_NETWORK_TIMEZONE
+func LoadNetworkLocationFromEnv() (*time.Location, error) {
+	if name := os.Getenv("NETWORK_TIMEZONE"); name != "" {
+		return LoadNetworkLocation(name)
+	}
+	return defaultNetworkLocation(), nil
+}
+
+// defaultNetworkLocation returns the default network timezone
+// Singapore has been on a fixed offset of +08:00 since 1982, so it is used if the timezone can't be loaded
+func defaultNetworkLocation() *time.Location {
+	location, err := time.LoadLocation(DEFAULT_NETWORK_TIMEZONE)
+	if err != nil {
+		return time.FixedZone(DEFAULT_NETWORK_TIMEZONE, 8*60*60)
+	}
+	return location
+}
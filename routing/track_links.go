@@ -0,0 +1,336 @@
+package routing
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+)
+
+// TrackLink is a direct train run between two adjacent stations, it is only travelled from the From station to the To station
+// unless it is bidirectional
+type TrackLink struct {
+	From          string // The station code
+	To            string
+	TimeInMinutes int64
+	Bidirectional bool
+}
+
+// LoadTrackLinks loads the track links from the csv file at the path
+func LoadTrackLinks(trackLinksPath string) ([]*TrackLink, error) {
+	trackLinksFile, err := os.Open(trackLinksPath)
+	if err != nil {
+		return nil, err
+	}
+	defer trackLinksFile.Close()
+	return NewTrackLinks(trackLinksFile)
+}
+
+// NewTrackLinks reads the track links from the csv reader
+// The csv has a header row followed by rows of the two station codes, the run time in minutes and whether the trains run both ways e.g.
+/*
+From Code,To Code,Minutes,Bidirectional
+EW4,CG1,3,true
+*/
+func NewTrackLinks(r io.Reader) ([]*TrackLink, error) {
+	csvReader := csv.NewReader(r)
+	csvReader.FieldsPerRecord = 4
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid track links file: %v", err)
+	}
+	var trackLinks []*TrackLink
+	for idx, record := range records {
+		if idx == 0 {
+			continue // skip the header row
+		}
+		trackLink := &TrackLink{From: strings.ToUpper(strings.TrimSpace(record[0])), To: strings.ToUpper(strings.TrimSpace(record[1]))}
+		trackLink.TimeInMinutes, err = strconv.ParseInt(strings.TrimSpace(record[2]), 10, 64)
+		if err != nil || trackLink.TimeInMinutes <= 0 {
+			return nil, fmt.Errorf("invalid run time %s between %s and %s", record[2], trackLink.From, trackLink.To)
+		}
+		trackLink.Bidirectional, err = strconv.ParseBool(strings.TrimSpace(record[3]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid bidirectional flag %s between %s and %s", record[3], trackLink.From, trackLink.To)
+		}
+		trackLinks = append(trackLinks, trackLink)
+	}
+	return trackLinks, nil
+}
+
+// WithTrackLinks returns a copy of the network on which the trains only run along the track links instead of between the
+// stations with consecutive numbers on each line. This models the branches, loops and the trains running through to another line
+// The run time of a track link is used instead of the time to the next station of the time rules, which still decide whether
+// the line is operational
+func (nw *Network) WithTrackLinks(trackLinks []*TrackLink) (*Network, error) {
+	trackNetwork := *nw
+	trackNetwork.trackTimes = map[string]int64{}
+	trackNetwork.trackNextCodes = map[string][]string{}
+	trackNetwork.trackPrevCodes = map[string][]string{}
+	for _, trackLink := range trackLinks {
+		for _, stationCode := range []string{trackLink.From, trackLink.To} {
+			if _, ok := nw.stationCodeNameMap[stationCode]; !ok {
+				return nil, fmt.Errorf("invalid station code %s in track links", stationCode)
+			}
+		}
+		if nw.stationCodeNameMap[trackLink.From] == nw.stationCodeNameMap[trackLink.To] {
+			return nil, fmt.Errorf("track link between %s and %s should be between different stations", trackLink.From, trackLink.To)
+		}
+		trackNetwork.addTrackRun(trackLink.From, trackLink.To, trackLink.TimeInMinutes)
+		if trackLink.Bidirectional {
+			trackNetwork.addTrackRun(trackLink.To, trackLink.From, trackLink.TimeInMinutes)
+		}
+	}
+	// The runs passing through the stations in between are precomputed as the route search estimates them for every segment
+	trackNetwork.trackRunTimes = map[string]int64{}
+	trackNetwork.trackRunPrevCodes = map[string]string{}
+	for stationCode := range trackNetwork.trackNextCodes {
+		trackNetwork.addTrackRunsFrom(nw.station(stationCode))
+	}
+	if err := validateNetwork(&trackNetwork); err != nil {
+		return nil, err
+	}
+	return &trackNetwork, nil
+}
+
+func (nw *Network) addTrackRun(fromCode, toCode string, timeInMinutes int64) {
+	if _, ok := nw.trackTimes[segmentKey(fromCode, toCode)]; !ok {
+		nw.trackNextCodes[fromCode] = append(nw.trackNextCodes[fromCode], toCode)
+		nw.trackPrevCodes[toCode] = append(nw.trackPrevCodes[toCode], fromCode)
+	}
+	nw.trackTimes[segmentKey(fromCode, toCode)] = timeInMinutes
+}
+
+// hasTrackLinks checks whether the trains run along the track links instead of the lines inferred from the station codes
+func (nw *Network) hasTrackLinks() bool {
+	return nw.trackTimes != nil
+}
+
+// trackStations returns the stations that the trains run to directly from the station, or the stations that the trains run
+// from directly to the station if backward
+func (nw *Network) trackStations(station *common.Station, backward bool) []*common.Station {
+	stationCodes := nw.trackNextCodes[station.Code]
+	if backward {
+		stationCodes = nw.trackPrevCodes[station.Code]
+	}
+	var stations []*common.Station
+	for _, stationCode := range stationCodes {
+		stations = append(stations, nw.station(stationCode))
+	}
+	return stations
+}
+
+// addTrackRunsFrom finds the runs with the shortest run time from the start station to every station the trains can reach from it
+func (nw *Network) addTrackRunsFrom(startStation *common.Station) {
+	queue := &routeQueue{}
+	queue.push(&common.RouteNode{Station: startStation}, routeCost{})
+	settledStations := map[string]bool{}
+	for queue.Len() > 0 {
+		routeNode := queue.pop()
+		if settledStations[routeNode.Station.Code] {
+			continue
+		}
+		settledStations[routeNode.Station.Code] = true
+		if routeNode.PrevNode != nil {
+			nw.trackRunTimes[segmentKey(startStation.Code, routeNode.Station.Code)] = routeNode.EstimatedTime
+			nw.trackRunPrevCodes[segmentKey(startStation.Code, routeNode.Station.Code)] = routeNode.PrevNode.Station.Code
+		}
+		for _, nextStation := range nw.trackStations(routeNode.Station, false) {
+			if settledStations[nextStation.Code] {
+				continue
+			}
+			estimatedTime := routeNode.EstimatedTime + nw.trackTimes[segmentKey(routeNode.Station.Code, nextStation.Code)]
+			queue.push(&common.RouteNode{Station: nextStation, EstimatedTime: estimatedTime, PrevNode: routeNode}, routeCost{primary: estimatedTime})
+		}
+	}
+}
+
+// trackPath returns the stations from the start station to the end station along the track links with the shortest run time,
+// nil if the trains don't run between them. The stations in between are the ones that the trains pass through without stopping
+func (nw *Network) trackPath(startStation, endStation *common.Station) []*common.Station {
+	if _, ok := nw.trackTimes[segmentKey(startStation.Code, endStation.Code)]; ok {
+		return []*common.Station{startStation, endStation}
+	}
+	if _, ok := nw.trackRunTimes[segmentKey(startStation.Code, endStation.Code)]; !ok {
+		return nil
+	}
+	stations := []*common.Station{endStation}
+	for stationCode := endStation.Code; stationCode != startStation.Code; {
+		stationCode = nw.trackRunPrevCodes[segmentKey(startStation.Code, stationCode)]
+		stations = append([]*common.Station{nw.station(stationCode)}, stations...)
+	}
+	return stations
+}
+
+// trackRunTime returns the run time in minutes from the start station to the end station including the stations passed through
+// without stopping, false if the trains don't run between them
+func (nw *Network) trackRunTime(startStationCode, endStationCode string) (int64, bool) {
+	if timeInMinutes, ok := nw.trackTimes[segmentKey(startStationCode, endStationCode)]; ok {
+		return timeInMinutes, true
+	}
+	if nw.stationCodeNameMap[startStationCode] == nw.stationCodeNameMap[endStationCode] {
+		return 0, false // Changing lines at the same station isn't a run
+	}
+	timeInMinutes, ok := nw.trackRunTimes[segmentKey(startStationCode, endStationCode)]
+	return timeInMinutes, ok
+}
+
+// trackContinuation returns the station that the trains continue to after running from the station to the next station
+// At a junction the trains are assumed to stay on the line of the next station, nil if the line ends or the way on is ambiguous
+func (nw *Network) trackContinuation(station, nextStation *common.Station) *common.Station {
+	var candidates, lineCandidates []*common.Station
+	for _, candidate := range nw.trackStations(nextStation, false) {
+		if candidate.Code == station.Code {
+			continue // The trains don't reverse
+		}
+		candidates = append(candidates, candidate)
+		if candidate.Code[:2] == nextStation.Code[:2] {
+			lineCandidates = append(lineCandidates, candidate)
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+	if len(lineCandidates) == 1 {
+		return lineCandidates[0]
+	}
+	return nil
+}
+
+// isOnTrackLinks checks whether the trains run to or from the station, every station is on the lines without the track links
+func (nw *Network) isOnTrackLinks(station *common.Station) bool {
+	if !nw.hasTrackLinks() {
+		return true
+	}
+	return len(nw.trackNextCodes[station.Code]) > 0 || len(nw.trackPrevCodes[station.Code]) > 0
+}
+
+// trackLineNeighbours returns the stations of the same line that the trains run to or from directly from the station ordered by the station number
+func (nw *Network) trackLineNeighbours(station *common.Station) []*common.Station {
+	neighbourCodes := map[string]bool{}
+	for _, stationCode := range append(append([]string{}, nw.trackNextCodes[station.Code]...), nw.trackPrevCodes[station.Code]...) {
+		if stationCode[:2] == station.Code[:2] {
+			neighbourCodes[stationCode] = true
+		}
+	}
+	var neighbours []*common.Station
+	for _, lineStation := range nw.trackLineCandidates(station.Code[:2]) {
+		if neighbourCodes[lineStation.Code] {
+			neighbours = append(neighbours, lineStation)
+		}
+	}
+	return neighbours
+}
+
+// trackLineCandidates returns the stations of the line on the track links ordered by the station number
+func (nw *Network) trackLineCandidates(lineCode string) []*common.Station {
+	var stationNumbers []int64
+	for stationNumber := range nw.trainLine[lineCode] {
+		stationNumbers = append(stationNumbers, stationNumber)
+	}
+	sort.Slice(stationNumbers, func(i, j int) bool { return stationNumbers[i] < stationNumbers[j] })
+	var stations []*common.Station
+	for _, stationNumber := range stationNumbers {
+		if station := nw.trainLine[lineCode][stationNumber]; nw.isOnTrackLinks(station) {
+			stations = append(stations, station)
+		}
+	}
+	return stations
+}
+
+// trackLineEnds returns the stations of the line on the track links that the line runs to from only one station of the line
+// A loop has no ends
+func (nw *Network) trackLineEnds(lineCode string) []*common.Station {
+	var ends []*common.Station
+	for _, station := range nw.trackLineCandidates(lineCode) {
+		if len(nw.trackLineNeighbours(station)) <= 1 {
+			ends = append(ends, station)
+		}
+	}
+	return ends
+}
+
+// trackLineStations returns the stations of the line on the track links in the order of travel along the line from its end with
+// the lowest station number, or from its lowest station number if it is a loop. At a junction the way on with the lower station number is listed first
+func (nw *Network) trackLineStations(lineCode string) []*common.Station {
+	candidates := nw.trackLineCandidates(lineCode)
+	starts := append(nw.trackLineEnds(lineCode), candidates...)
+	var stations []*common.Station
+	visitedStations := map[string]bool{}
+	for _, start := range starts {
+		if visitedStations[start.Code] {
+			continue
+		}
+		pendingStations := []*common.Station{start}
+		for len(pendingStations) > 0 {
+			station := pendingStations[len(pendingStations)-1]
+			pendingStations = pendingStations[:len(pendingStations)-1]
+			if visitedStations[station.Code] {
+				continue
+			}
+			visitedStations[station.Code] = true
+			stations = append(stations, station)
+			neighbours := nw.trackLineNeighbours(station)
+			for idx := len(neighbours) - 1; idx >= 0; idx-- {
+				if !visitedStations[neighbours[idx].Code] {
+					pendingStations = append(pendingStations, neighbours[idx])
+				}
+			}
+		}
+	}
+	return stations
+}
+
+// trackSegment returns the stations along the track links between the stations in either direction, nil if they aren't linked
+func (nw *Network) trackSegment(station, otherStation *common.Station) []*common.Station {
+	if station == nil || otherStation == nil {
+		return nil
+	}
+	if stations := nw.trackPath(station, otherStation); stations != nil {
+		return stations
+	}
+	return nw.trackPath(otherStation, station)
+}
+
+// trackLinkKeys returns the keys of the track links between the consecutive stations in either direction
+func trackLinkKeys(stations []*common.Station) map[string]bool {
+	linkKeys := map[string]bool{}
+	for idx := 0; idx+1 < len(stations); idx++ {
+		startCode, endCode := stations[idx].Code, stations[idx+1].Code
+		if startCode > endCode {
+			startCode, endCode = endCode, startCode
+		}
+		linkKeys[segmentKey(startCode, endCode)] = true
+	}
+	return linkKeys
+}
+
+// rideLine returns the train line code ridden between the stations. A run through to another line is ridden on the line that
+// serves both the stations e.g. EW4 Tanah Merah to CG1 Expo is on the CG line as CG0 is Tanah Merah too, else on the line of the start station
+func (nw *Network) rideLine(startStationCode, endStationCode string) string {
+	startLineCode, endLineCode := startStationCode[:2], endStationCode[:2]
+	if startLineCode == endLineCode {
+		return startLineCode
+	}
+	for _, lineCode := range []string{startLineCode, endLineCode} {
+		if nw.isStationOnLine(startStationCode, lineCode) && nw.isStationOnLine(endStationCode, lineCode) {
+			return lineCode
+		}
+	}
+	return startLineCode
+}
+
+// isStationOnLine checks whether any of the platforms of the station of the station code is on the train line
+func (nw *Network) isStationOnLine(stationCode, lineCode string) bool {
+	for _, platformCode := range nw.stationNameCodeMap[nw.stationCodeNameMap[stationCode]] {
+		if platformCode[:2] == lineCode {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The AA line branches at Beta to the BB line whose stub BB0 Beta isn't used, AA4 is missing between Gamma and Epsilon and the
+// trains only run from Gamma to Epsilon and from Epsilon to Alpha
+const testTrackStationMap = `Station Code,Station Name,Opening Date
+AA1,Alpha,2000
+AA2,Beta,2000
+AA3,Gamma,2000
+AA5,Epsilon,2000
+BB0,Beta,2000
+BB1,Delta,2000
+BB2,Zeta,2030
+BB3,Eta,2000
+LL1,Lambda,2000
+LL2,Mu,2000
+LL3,Nu,2000
+`
+
+const testTrackLinks = `From Code,To Code,Minutes,Bidirectional
+AA1,AA2,3,true
+AA2,AA3,4,true
+AA2,BB1,5,true
+BB1,BB2,2,true
+BB2,BB3,2,true
+AA3,AA5,4,false
+AA5,AA1,6,false
+LL1,LL2,3,true
+LL2,LL3,3,true
+LL3,LL1,3,true
+`
+
+func newTestTrackNetwork(t *testing.T) *Network {
+	nw, err := NewNetwork(strings.NewReader(testTrackStationMap), TrainLineTimeExceptionRules)
+	assert.Nil(t, err)
+	trackLinks, err := NewTrackLinks(strings.NewReader(testTrackLinks))
+	assert.Nil(t, err)
+	nw, err = nw.WithTrackLinks(trackLinks)
+	assert.Nil(t, err)
+	return nw
+}
+
+func TestTrackLinks(t *testing.T) {
+	nw := newTestTrackNetwork(t)
+	planner := NewPlanner(nw, WithLocation(time.UTC))
+	startTime := parseTestTime(t, "2022-01-31T12:00")
+
+	t.Run("reads the track links", func(t *testing.T) {
+		trackLinks, err := NewTrackLinks(strings.NewReader("From Code,To Code,Minutes,Bidirectional\new4, CG1,3,true\nCG1,CG2,4,false\n"))
+		assert.Nil(t, err)
+		assert.Equal(t, []*TrackLink{{From: "EW4", To: "CG1", TimeInMinutes: 3, Bidirectional: true}, {From: "CG1", To: "CG2", TimeInMinutes: 4}}, trackLinks)
+	})
+
+	t.Run("runs through the branch without changing lines", func(t *testing.T) {
+		journeys, err := planner.Plan(context.Background(), "Alpha", "Delta", WithStartTime(startTime))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"AA1", "AA2", "BB1"}, journeys[0].StationCodes())
+		assert.Equal(t, int64(2), journeys[0].StationsTravelled)
+		assert.Equal(t, int64(0), journeys[0].TransferCount)
+		assert.Equal(t, int64(8), journeys[0].EstimatedTimeInMinutes)
+		assert.Equal(t, []string{"Take AA line from Alpha to Beta towards Epsilon", "Take BB line from Beta to Delta towards Eta"}, journeys[0].Instructions)
+		// The leg is split where the train runs through to the BB line that Beta and Delta are both on
+		assert.Equal(t, []string{"AA", "BB"}, journeys[0].Lines)
+		assert.Equal(t, 2, len(journeys[0].Legs))
+		assert.Equal(t, "BB", journeys[0].Legs[1].Line)
+		assert.Equal(t, int64(5), journeys[0].Legs[1].TimeInMinutes)
+		assert.Equal(t, "Take AA line towards Epsilon for 1 stop to Beta, continue on BB line towards Eta for 1 stop to Delta", journeys[0].Summary)
+
+		arrivalJourneys, err := planner.Plan(context.Background(), "Alpha", "Delta", WithArriveBy(startTime))
+		assert.Nil(t, err)
+		assert.Equal(t, journeys[0].StationCodes(), arrivalJourneys[0].StationCodes())
+		assert.Equal(t, int64(8), arrivalJourneys[0].EstimatedTimeInMinutes)
+	})
+
+	t.Run("passes through the stations that haven't opened", func(t *testing.T) {
+		journeys, err := planner.Plan(context.Background(), "Delta", "Eta", WithStartTime(startTime))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"BB1", "BB3"}, journeys[0].StationCodes())
+		assert.Equal(t, int64(4), journeys[0].EstimatedTimeInMinutes)
+		assert.Equal(t, 2*DEFAULT_SEGMENT_DISTANCE_IN_KM, nw.rideDistance(nw.station("BB1"), nw.station("BB3")))
+	})
+
+	t.Run("travels around the loop", func(t *testing.T) {
+		journeys, err := planner.Plan(context.Background(), "Lambda", "Nu", WithStartTime(startTime))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"LL1", "LL3"}, journeys[0].StationCodes())
+		assert.Equal(t, "Mu", journeys[0].Legs[0].Terminus.Name)
+	})
+
+	t.Run("only runs between the linked stations in the direction of the link", func(t *testing.T) {
+		journeys, err := planner.Plan(context.Background(), "Gamma", "Epsilon", WithStartTime(startTime))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"AA3", "AA5"}, journeys[0].StationCodes())
+		assert.Equal(t, int64(4), journeys[0].EstimatedTimeInMinutes)
+		journeys, err = planner.Plan(context.Background(), "Epsilon", "Gamma", WithStartTime(startTime))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"AA5", "AA1", "AA2", "AA3"}, journeys[0].StationCodes())
+		assert.Equal(t, int64(13), journeys[0].EstimatedTimeInMinutes)
+	})
+
+	t.Run("finds the terminus along the track links", func(t *testing.T) {
+		networkDate := time.Date(2022, 1, 31, 0, 0, 0, 0, time.UTC)
+		testCases := []struct {
+			from     string
+			to       string
+			terminus string
+		}{
+			{from: "AA1", to: "AA2", terminus: "Epsilon"}, // Stays on the line at the junction until the line comes back to Alpha
+			{from: "AA2", to: "AA1", terminus: "Alpha"},   // The trains don't run from Alpha to Epsilon
+			{from: "AA2", to: "BB1", terminus: "Eta"},
+			{from: "BB1", to: "BB3", terminus: "Eta"},
+			{from: "BB1", to: "AA2", terminus: "Beta"}, // The way on from the junction is ambiguous
+		}
+		for _, testCase := range testCases {
+			assert.Equal(t, testCase.terminus, nw.terminus(nw.station(testCase.from), nw.station(testCase.to), networkDate).Name, testCase.from+" to "+testCase.to)
+		}
+	})
+
+	t.Run("returns the stations of the lines along the track links", func(t *testing.T) {
+		line, ok := nw.Line("AA")
+		assert.True(t, ok)
+		var stationCodes []string
+		for _, station := range line.Stations {
+			stationCodes = append(stationCodes, station.Code)
+		}
+		assert.Equal(t, []string{"AA1", "AA2", "AA3", "AA5"}, stationCodes)
+		assert.Empty(t, line.Termini) // The line is a loop
+		assert.Empty(t, line.Interchanges)
+
+		line, ok = nw.Line("BB")
+		assert.True(t, ok)
+		assert.Equal(t, 3, line.StationCount) // BB0 isn't on the track links
+		assert.Equal(t, "BB1", line.Stations[0].Code)
+		assert.Equal(t, []string{"Delta", "Eta"}, line.Termini)
+	})
+
+	t.Run("closes the segments along the track links", func(t *testing.T) {
+		closedSegment := &Disruption{ID: "1", Type: SEGMENT_DISRUPTION, From: "BB1", To: "BB3", Start: startTime.Add(-time.Hour)}
+		assert.Nil(t, nw.ValidateDisruption(closedSegment))
+		assert.Equal(t, []string{"Delta", "Zeta", "Eta"}, closedSegment.stationNames(nw))
+		disruptedPlanner := NewPlanner(nw, WithLocation(time.UTC), WithDisruptions(testDisruptions{closedSegment}))
+		journeys, err := disruptedPlanner.Plan(context.Background(), "Eta", "Delta", WithStartTime(startTime))
+		assert.Nil(t, err)
+		assert.Empty(t, journeys)
+		journeys, err = disruptedPlanner.Plan(context.Background(), "Alpha", "Delta", WithStartTime(startTime))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"AA1", "AA2", "BB1"}, journeys[0].StationCodes())
+
+		err = nw.ValidateDisruption(&Disruption{Type: SEGMENT_DISRUPTION, From: "BB0", To: "BB1", Start: startTime})
+		assert.Equal(t, &InvalidRequestError{Message: "stations BB0 and BB1 of disruption aren't linked by the track links"}, err)
+	})
+
+	t.Run("returns an error for invalid track links", func(t *testing.T) {
+		_, err := NewTrackLinks(strings.NewReader("From Code,To Code,Minutes,Bidirectional\nAA1,AA2,0,true\n"))
+		assert.Equal(t, fmt.Errorf("invalid run time 0 between AA1 and AA2"), err)
+		_, err = NewTrackLinks(strings.NewReader("From Code,To Code,Minutes,Bidirectional\nAA1,AA2,3,both\n"))
+		assert.Equal(t, fmt.Errorf("invalid bidirectional flag both between AA1 and AA2"), err)
+
+		baseNetwork, err := NewNetwork(strings.NewReader(testTrackStationMap), TrainLineTimeExceptionRules)
+		assert.Nil(t, err)
+		_, err = baseNetwork.WithTrackLinks([]*TrackLink{{From: "AA1", To: "AA4", TimeInMinutes: 3}})
+		assert.Equal(t, fmt.Errorf("invalid station code AA4 in track links"), err)
+		_, err = baseNetwork.WithTrackLinks([]*TrackLink{{From: "AA2", To: "BB0", TimeInMinutes: 3}})
+		assert.Equal(t, fmt.Errorf("track link between AA2 and BB0 should be between different stations"), err)
+		_, err = baseNetwork.WithTrackLinks([]*TrackLink{{From: "AA1", To: "AA2", TimeInMinutes: 3}})
+		assert.Equal(t, fmt.Errorf("trains can't run to station AA1 on the track links"), err)
+		_, err = baseNetwork.WithTrackLinks([]*TrackLink{{From: "AA1", To: "AA2", TimeInMinutes: 3}, {From: "AA3", To: "AA1", TimeInMinutes: 3}})
+		assert.Equal(t, fmt.Errorf("trains can't run from station AA2 on the track links"), err)
+	})
+}
@@ -0,0 +1,76 @@
+package routing
+
+import (
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+)
+
+// Network is the train network along with the rules used to find the routes
+// A network is never modified once it is built, so it can be shared between requests and a reload builds a new network
+type Network struct {
+	trainLine           map[string]map[int64]*common.Station // Key is train line code and value is the map of station number to station
+	stationNameCodeMap  map[string][]string                  // Key is station name and value is a list of station codes mapped to it
+	stationCodeNameMap  map[string]string                    // Reverse map of stationNameCodeMap. Key is station code and value is station name
+	timeRules           TimeExceptionRule
+	holidays            HolidayCalendar     // The holidays on which the holiday time rules apply
+	stationIndex        *stationIndex       // Used to search the station names that may not be an exact match
+	fareTable           *FareTable          // The fares of the journeys aren't calculated if it is nil
+	segmentDistances    SegmentDistances    // The distances between the adjacent stations used for the fares
+	walkingTimes        map[string]int64    // Keyed by the segment key of the station codes walked between and value is the time in minutes
+	walkingStationCodes map[string][]string // Key is station code and value is the list of station codes that can be walked to from it
+	coordinates         StationCoordinates  // The locations of the stations, nil if the network doesn't have coordinates
+	coordinateIndex     *spatialIndex       // Used to find the stations nearest to a location, nil if the network doesn't have coordinates
+	trackTimes          map[string]int64    // Keyed by the segment key of the station codes of a track link and value is the run time in minutes, nil if the lines are inferred from the station codes
+	trackNextCodes      map[string][]string // Key is station code and value is the list of station codes that the trains run to from it
+	trackPrevCodes      map[string][]string // Key is station code and value is the list of station codes that the trains run from to it
+	trackRunTimes       map[string]int64    // Keyed by the segment key of the station codes and value is the shortest run time between them along the track links
+	trackRunPrevCodes   map[string]string   // Keyed by the segment key of the station codes and value is the station code before the end station on the shortest run
+}
+
+// NetworkProvider provides the network to be used for a request
+type NetworkProvider interface {
+	Network() *Network
+}
+
+// Network returns the network itself so that a network can be injected wherever a NetworkProvider is expected
+func (nw *Network) Network() *Network {
+	return nw
+}
+
+// WithHolidayCalendar returns a copy of the network that evaluates the time rules with the holiday calendar
+func (nw *Network) WithHolidayCalendar(holidays HolidayCalendar) *Network {
+	holidayNetwork := *nw
+	holidayNetwork.holidays = holidays
+	return &holidayNetwork
+}
+
+// Stats returns the number of stations and train lines in the network
+func (nw *Network) Stats() (int, int) {
+	return len(nw.stationCodeNameMap), len(nw.trainLine)
+}
+
+// Metadata for train line
+type TrainLineMeta struct {
+	NextStationTimeInMinutes int64
+	LineChangeTimeInMinutes  int64
+	IsNotOperational         bool
+	DaysOfWeek               []string
+	DayTypes                 []string // HOLIDAY_DAY_TYPE, WEEKEND_DAY_TYPE or a date in DATE_FORMAT to which the time range config applies
+}
+
+// TimeExceptionRule has the time rules of each train line keyed by the train line code
+// The rules of the DEFAULT_KEY train line apply to the train lines that don't have rules of their own
+type TimeExceptionRule map[string]*LineTimeRules
+
+// LineTimeRules has the time rules of a train line
+type LineTimeRules struct {
+	Default *TrainLineMeta // Applies when none of the rules apply, the default of the DEFAULT_KEY train line is used if it is nil
+	Rules   []*TimeRule
+}
+
+// TimeRule applies the train line meta during its time range on the days of the train line meta
+type TimeRule struct {
+	Start    string // The start of the time range in time.Kitchen format e.g. "10:00PM", it is included in the range
+	End      string // The end of the time range e.g. "6:00AM", it is excluded from the range. The range wraps past midnight if the end isn't after the start
+	Priority int    // The rule with the highest priority is used when more than one rule applies
+	TrainLineMeta
+}
@@ -0,0 +1,117 @@
+package routing
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+)
+
+// WalkingLink is a walk outside the stations between two nearby stations, it can be walked in either direction
+type WalkingLink struct {
+	From          string // The station name or code, a station name links all of its platforms
+	To            string
+	TimeInMinutes int64
+}
+
+// LoadWalkingLinks loads the walking links from the csv file at the path
+func LoadWalkingLinks(walkingLinksPath string) ([]*WalkingLink, error) {
+	walkingLinksFile, err := os.Open(walkingLinksPath)
+	if err != nil {
+		return nil, err
+	}
+	defer walkingLinksFile.Close()
+	return NewWalkingLinks(walkingLinksFile)
+}
+
+// NewWalkingLinks reads the walking links from the csv reader
+// The csv has a header row followed by rows of the two stations and the time in minutes to walk between them e.g.
+/*
+Station A,Station B,Minutes
+Bras Basah,Bencoolen,5
+*/
+func NewWalkingLinks(r io.Reader) ([]*WalkingLink, error) {
+	csvReader := csv.NewReader(r)
+	csvReader.FieldsPerRecord = 3
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid walking links file: %v", err)
+	}
+	var walkingLinks []*WalkingLink
+	for idx, record := range records {
+		if idx == 0 {
+			continue // skip the header row
+		}
+		walkingLink := &WalkingLink{From: strings.TrimSpace(record[0]), To: strings.TrimSpace(record[1])}
+		walkingLink.TimeInMinutes, err = strconv.ParseInt(strings.TrimSpace(record[2]), 10, 64)
+		if err != nil || walkingLink.TimeInMinutes <= 0 {
+			return nil, fmt.Errorf("invalid walking time %s between %s and %s", record[2], walkingLink.From, walkingLink.To)
+		}
+		walkingLinks = append(walkingLinks, walkingLink)
+	}
+	return walkingLinks, nil
+}
+
+// WithWalkingLinks returns a copy of the network on which the routes can walk between the stations of the walking links
+func (nw *Network) WithWalkingLinks(walkingLinks []*WalkingLink) (*Network, error) {
+	walkingNetwork := *nw
+	walkingNetwork.walkingTimes = map[string]int64{}
+	walkingNetwork.walkingStationCodes = map[string][]string{}
+	for _, walkingLink := range walkingLinks {
+		fromCodes, ok := nw.resolveStationCodes(walkingLink.From)
+		if !ok {
+			return nil, fmt.Errorf("invalid station %s in walking links", walkingLink.From)
+		}
+		toCodes, ok := nw.resolveStationCodes(walkingLink.To)
+		if !ok {
+			return nil, fmt.Errorf("invalid station %s in walking links", walkingLink.To)
+		}
+		if nw.stationCodeNameMap[fromCodes[0]] == nw.stationCodeNameMap[toCodes[0]] {
+			return nil, fmt.Errorf("walking link between %s and %s should be between different stations", walkingLink.From, walkingLink.To)
+		}
+		for _, fromCode := range fromCodes {
+			for _, toCode := range toCodes {
+				walkingNetwork.addWalk(fromCode, toCode, walkingLink.TimeInMinutes)
+				walkingNetwork.addWalk(toCode, fromCode, walkingLink.TimeInMinutes)
+			}
+		}
+	}
+	return &walkingNetwork, nil
+}
+
+func (nw *Network) addWalk(fromCode, toCode string, timeInMinutes int64) {
+	if _, ok := nw.walkingTimes[segmentKey(fromCode, toCode)]; !ok {
+		nw.walkingStationCodes[fromCode] = append(nw.walkingStationCodes[fromCode], toCode)
+	}
+	nw.walkingTimes[segmentKey(fromCode, toCode)] = timeInMinutes
+}
+
+// walkingTime returns the time in minutes to walk between the stations, false if there's no walking link between them
+func (nw *Network) walkingTime(startStationCode, endStationCode string) (int64, bool) {
+	timeInMinutes, ok := nw.walkingTimes[segmentKey(startStationCode, endStationCode)]
+	return timeInMinutes, ok
+}
+
+// walkingStations returns the stations that can be walked to from the station
+func (nw *Network) walkingStations(station *common.Station) []*common.Station {
+	var stations []*common.Station
+	for _, stationCode := range nw.walkingStationCodes[station.Code] {
+		stations = append(stations, nw.station(stationCode))
+	}
+	return stations
+}
+
+// isWalk checks whether travelling between the stations is a walk between nearby stations
+func (nw *Network) isWalk(station, nextStation *common.Station) bool {
+	_, ok := nw.walkingTime(station.Code, nextStation.Code)
+	return ok
+}
+
+// isTransfer checks whether travelling between the stations is a change of lines at the same station or a walk to another station
+func (nw *Network) isTransfer(station, nextStation *common.Station) bool {
+	return isLineChange(station, nextStation) || nw.isWalk(station, nextStation)
+}
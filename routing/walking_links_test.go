@@ -0,0 +1,56 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalkingLinks(t *testing.T) {
+	walkingLinks, err := NewWalkingLinks(strings.NewReader("Station A,Station B,Minutes\nBras Basah,Bencoolen,5\nEsplanade,City Hall,7\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, []*WalkingLink{{From: "Bras Basah", To: "Bencoolen", TimeInMinutes: 5}, {From: "Esplanade", To: "City Hall", TimeInMinutes: 7}}, walkingLinks)
+	nw, err := newTestNetwork(t).WithWalkingLinks(walkingLinks)
+	assert.Nil(t, err)
+	planner := NewPlanner(nw)
+
+	t.Run("walks between the nearby stations", func(t *testing.T) {
+		journeys, err := planner.Plan(context.Background(), "Bras Basah", "Bencoolen", WithStartTime(parseTestTime(t, "2022-01-31T12:00")))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"CC2", "DT21"}, journeys[0].StationCodes())
+		assert.Equal(t, []string{"Walk from Bras Basah to Bencoolen"}, journeys[0].Instructions)
+		assert.Equal(t, int64(5), journeys[0].EstimatedTimeInMinutes)
+		assert.Equal(t, int64(0), journeys[0].StationsTravelled)
+		assert.Equal(t, int64(1), journeys[0].TransferCount)
+		assert.Empty(t, journeys[0].Lines)
+	})
+
+	t.Run("walks to any platform of the station in either direction", func(t *testing.T) {
+		journeys, err := planner.Plan(context.Background(), "Raffles Place", "Promenade", WithStartTime(parseTestTime(t, "2022-01-31T12:00")))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"EW14", "EW13", "CC3", "CC4"}, journeys[0].StationCodes())
+		assert.Equal(t, "Walk from City Hall to Esplanade", journeys[0].Instructions[1])
+		assert.Equal(t, []string{"EW", "CC"}, journeys[0].Lines)
+	})
+
+	t.Run("returns an error for an invalid walking link", func(t *testing.T) {
+		testCases := []struct {
+			walkingLinks string
+			err          error
+		}{
+			{walkingLinks: "Station A,Station B,Minutes\nBras Basah,Bencoolen,five\n", err: fmt.Errorf("invalid walking time five between Bras Basah and Bencoolen")},
+			{walkingLinks: "Station A,Station B,Minutes\nBras Basah,Atlantis,5\n", err: fmt.Errorf("invalid station Atlantis in walking links")},
+			{walkingLinks: "Station A,Station B,Minutes\nCity Hall,EW13,5\n", err: fmt.Errorf("walking link between City Hall and EW13 should be between different stations")},
+		}
+		for _, testCase := range testCases {
+			walkingLinks, err := NewWalkingLinks(strings.NewReader(testCase.walkingLinks))
+			if err == nil {
+				_, err = newTestNetwork(t).WithWalkingLinks(walkingLinks)
+			}
+			assert.Equal(t, testCase.err, err)
+		}
+	})
+}
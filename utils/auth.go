@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// RequireAdminToken only calls the handler for the requests with the admin token in the "Authorization: Bearer <token>" header
+// Every request is rejected if the admin token is empty so that the admin endpoints are never open by mistake
+func RequireAdminToken(adminToken string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if adminToken == "" {
+			WriteErrorResponse(fmt.Errorf("admin endpoints are disabled as the admin token isn't configured"), w, 403)
+			return
+		}
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			WriteErrorResponse(fmt.Errorf("invalid admin token"), w, 401)
+			return
+		}
+		handler(w, r)
+	}
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+)
+
+// WriteErrorResponse writes the error as the json response with the status code
+func WriteErrorResponse(err error, w http.ResponseWriter, statusCode int) {
+	WriteErrorResponseWithSuggestions(err, nil, w, statusCode)
+}
+
+// WriteErrorResponseWithSuggestions writes the error along with the suggestions to correct the request as the json response with the status code
+func WriteErrorResponseWithSuggestions(err error, suggestions []string, w http.ResponseWriter, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	errResp := &common.ErrorResponse{
+		Code:        statusCode,
+		Message:     err.Error(),
+		Suggestions: suggestions,
+	}
+	_ = json.NewEncoder(w).Encode(errResp)
+}
+
+// WriteSuccessResponse writes the response as json with the status code
+func WriteSuccessResponse(w http.ResponseWriter, statusCode int, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -1,14 +1,80 @@
 package utils
 
-import "strconv"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.myteksi.net/goscripts/zendesk/common"
+)
+
+// openingDateFormats are the formats in which the opening date of a station can be present in the station map along with the
+// length of the period that they denote in years, months and days. Ordered from the most to the least precise format
+var openingDateFormats = []struct {
+	layout string
+	years  int
+	months int
+	days   int
+}{
+	{layout: "2 January 2006", days: 1},
+	{layout: "January 2006", months: 1},
+	{layout: "2006", years: 1},
+}
 
 // GetStationMetadataFromCode returns the train line name and station code in integer format
 // Assumes that the train line is a 2 character value which is a prefix of stationcode and stationcode is always a number
 // The logic can be enhanced further by either having another field for trainline or add these constraints on stationcode naming
 func GetStationMetadataFromCode(stationCode string) (string, int64, error) {
+	if len(stationCode) < 3 {
+		return "", 0, fmt.Errorf("invalid station code %s", stationCode)
+	}
 	code, err := strconv.ParseInt(stationCode[2:], 10, 64)
 	if err != nil {
 		return "", 0, err
 	}
 	return stationCode[:2], code, nil
 }
+
+// ParseOpeningDate parses the opening date of a station present in the station map
+// Partial dates like "December 2019" are resolved to the last day of the period i.e. 31st December 2019, so that a station isn't
+// considered open before it actually opened
+// An empty opening date returns the zero time which denotes that the station has always been open
+func ParseOpeningDate(openingDate string) (time.Time, error) {
+	openingDate = strings.TrimSpace(openingDate)
+	if openingDate == "" {
+		return time.Time{}, nil
+	}
+	for _, format := range openingDateFormats {
+		date, err := time.Parse(format.layout, openingDate)
+		if err == nil {
+			return date.AddDate(format.years, format.months, format.days-1), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid opening date %s", openingDate)
+}
+
+// ParseLatLon parses the comma separated latitude and longitude of a location e.g. "1.3521,103.8198"
+func ParseLatLon(latLon string) (*common.Coordinates, error) {
+	values := strings.Split(latLon, ",")
+	if len(values) != 2 {
+		return nil, fmt.Errorf("invalid location %s", latLon)
+	}
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(values[0]), 64)
+	if err != nil {
+		return nil, err
+	}
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(values[1]), 64)
+	if err != nil {
+		return nil, err
+	}
+	return NewCoordinates(latitude, longitude)
+}
+
+// NewCoordinates returns the coordinates of the latitude and longitude if they are within their range
+func NewCoordinates(latitude, longitude float64) (*common.Coordinates, error) {
+	if !(latitude >= -90 && latitude <= 90) || !(longitude >= -180 && longitude <= 180) {
+		return nil, fmt.Errorf("invalid coordinates %v,%v", latitude, longitude)
+	}
+	return &common.Coordinates{Latitude: latitude, Longitude: longitude}, nil
+}